@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/razorcorp/postcode-sdk-go"
 	"github.com/razorcorp/postcode-sdk-go/postcode"
+	"time"
 )
 
 /**
@@ -193,8 +194,20 @@ func randomPlace() {
 	fmt.Printf("%#v\n", data)
 }
 
+func clientLookup() {
+	client := postcode.NewClient(
+		postcode.WithBaseURL("https://api.postcodes.io"),
+		postcode.WithTimeout(10*time.Second),
+	)
+	data, lookupError := client.Lookup("RG122PE")
+	if lookupError != nil {
+		panic(fmt.Sprintf("%#v", lookupError))
+	}
+	fmt.Printf("%#v\n", data)
+}
+
 func main() {
-	fmt.Printf("Version: %s\n", postcode.VERSION)
+	fmt.Printf("Version: %s\n", postcode_sdk_go.VERSION)
 
 	executor("Singe postcode lookup", postcodeLookup)
 
@@ -229,4 +242,6 @@ func main() {
 	executor("Place query lookup", placeQuery)
 
 	executor("Random place", randomPlace)
+
+	executor("Configured client lookup", clientLookup)
 }
@@ -1,3 +1,3 @@
 module github.com/razorcorp/postcode-sdk-go
 
-go 1.16
+go 1.21
@@ -1,5 +1,13 @@
 package model
 
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
 /**
  * Package name: model
  * Project name: postcode-sdk-go
@@ -7,9 +15,102 @@ package model
  * Created on: 28/05/2022 01:41
  */
 
+//Error categories of a ResponseError. Use errors.Is to branch on the cause of a failure, e.g.
+//
+//	if errors.Is(err, model.ErrNotFound) {
+//		...
+//	}
+var (
+	//ErrNotFound the requested resource does not exist (HTTP 404)
+	ErrNotFound = errors.New("not found")
+
+	//ErrInvalidPostcode the given postcode is not a valid UK postcode
+	ErrInvalidPostcode = errors.New("invalid postcode")
+
+	//ErrRateLimited the API throttled the request (HTTP 429)
+	ErrRateLimited = errors.New("rate limited")
+
+	//ErrServer the API failed to process the request (HTTP 5xx)
+	ErrServer = errors.New("server error")
+
+	//ErrTransport the request could not be delivered or the response could not be received
+	ErrTransport = errors.New("transport error")
+
+	//ErrDecode the response body could not be decoded
+	ErrDecode = errors.New("decode error")
+
+	//ErrValidation the request parameters were rejected by the SDK or the API (HTTP 400)
+	ErrValidation = errors.New("validation error")
+
+	//ErrRequest the request could not be built or its body could not be encoded, or the request or its response was
+	//refused by the Doer with an error wrapping ErrRequest, e.g. by postcode.MaxBodySize. Request errors are never
+	//retried
+	ErrRequest = errors.New("request error")
+
+	//ErrTruncated the results are incomplete, e.g. an area search hit the result limit of the API. The results found
+	//are returned along with the error
+	ErrTruncated = errors.New("truncated results")
+)
+
 type (
+	//ResponseError is the error returned by every SDK operation.
+	//
+	//Status holds the HTTP status returned by the API, or the status the API would have returned for the
+	//validation errors raised by the SDK. Status is 0 when the failure happened before a response was received.
 	ResponseError struct {
-		Status int    `json:"status"`
-		Error  string `json:"error"`
+		Status  int    `json:"status"`
+		Message string `json:"error"`
+
+		//Kind error category set by the SDK, e.g. ErrTransport. Categories derived from the Status are matched
+		//by errors.Is without Kind being set
+		Kind error `json:"-"`
+
+		//Err underlying cause of the error, if any
+		Err error `json:"-"`
+
+		//RetryAfter delay requested by the API through the Retry-After header, if any
+		RetryAfter time.Duration `json:"-"`
 	}
 )
+
+func (e *ResponseError) Error() string {
+	if e == nil {
+		return "<nil>"
+	}
+	if e.Status == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("%s (status %d)", e.Message, e.Status)
+}
+
+//Unwrap returns the underlying cause of the error
+func (e *ResponseError) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+	return e.Err
+}
+
+//Is reports whether the error belongs to the target error category
+func (e *ResponseError) Is(target error) bool {
+	if e == nil {
+		return false
+	}
+	if e.Kind != nil && target == e.Kind {
+		return true
+	}
+
+	switch target {
+	case ErrNotFound:
+		return e.Status == http.StatusNotFound
+	case ErrInvalidPostcode:
+		return e.Status == http.StatusNotFound && strings.EqualFold(e.Message, "Invalid postcode")
+	case ErrRateLimited:
+		return e.Status == http.StatusTooManyRequests
+	case ErrServer:
+		return e.Status >= http.StatusInternalServerError
+	case ErrValidation:
+		return e.Status == http.StatusBadRequest
+	}
+	return false
+}
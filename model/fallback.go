@@ -0,0 +1,9 @@
+package model
+
+type (
+	//Fallback marks a result served by the local dataset of a failover client instead of postcodes.io
+	Fallback struct {
+		//Vintage release of the dataset that answered, e.g. "2024-05". Empty when the dataset does not report it
+		Vintage string `json:"vintage,omitempty"`
+	}
+)
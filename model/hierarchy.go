@@ -0,0 +1,437 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	//areaPattern postcode areas of the standard formats with the letters allowed in each position
+	areaPattern = regexp.MustCompile(`^[A-PR-UWYZ][A-HK-Y]?$`)
+
+	//outcodePattern outward code formats A9, A99, A9A, AA9, AA99 and AA9A with the letters allowed in each position
+	outcodePattern = regexp.MustCompile(`^([A-PR-UWYZ][0-9][0-9A-HJKPSTUW]?|[A-PR-UWYZ][A-HK-Y][0-9][0-9ABEHMNPRV-Y]?)$`)
+
+	//incodePattern inward code format 9AA with the letters allowed in the unit
+	incodePattern = regexp.MustCompile(`^[0-9][ABD-HJLNP-UW-Z]{2}$`)
+
+	//nonGeographic postcodes outside of the standard format, keyed by outward code
+	nonGeographic = map[string]string{
+		"GIR":  "0AA", // Girobank
+		"ASCN": "1ZZ", // Ascension Island
+		"BBND": "1ZZ", // British Indian Ocean Territory
+		"BIQQ": "1ZZ", // British Antarctic Territory
+		"FIQQ": "1ZZ", // Falkland Islands
+		"PCRN": "1ZZ", // Pitcairn Islands
+		"SIQQ": "1ZZ", // South Georgia and the South Sandwich Islands
+		"STHL": "1ZZ", // Saint Helena
+		"TDCU": "1ZZ", // Tristan da Cunha
+		"TKCA": "1ZZ", // Turks and Caicos Islands
+	}
+)
+
+//bfpo outward code of the British Forces Post Office numbers, which have no sector and unit
+const bfpo = "BFPO"
+
+type (
+	//PostcodeLevel is a level of the postcode hierarchy: Area, District, Sector or Unit
+	PostcodeLevel interface {
+		fmt.Stringer
+
+		//Area returns the postcode area the level belongs to
+		Area() Area
+	}
+
+	//Area postcode area, the leading letters of the outward code, e.g. "RG". Area is the top of the hierarchy.
+	Area string
+
+	//District postcode district, the outward code, e.g. "RG12"
+	District string
+
+	//Sector postcode sector, the outward code and the first digit of the inward code, e.g. "RG12 2"
+	Sector string
+
+	//Unit postcode unit, the full postcode, e.g. "RG12 2PE"
+	Unit string
+
+	//districtParts district broken down for natural ordering, e.g. RG12 as RG, 12, ""
+	districtParts struct {
+		area   string
+		number int
+		suffix string
+	}
+)
+
+//ParseArea Returns the Area of the given text in canonical form. Case and white space are ignored.
+//
+//The levels of the hierarchy are validated against the Royal Mail format rules, like postcode.Parse. Besides the
+//standard formats GIR 0AA and the overseas territories postcodes such as ASCN 1ZZ are accepted, and BFPO as an area
+//and district.
+func ParseArea(text string) (Area, error) {
+	compact := compactCode(text)
+	if _, ok := nonGeographic[compact]; !ok && compact != bfpo && !areaPattern.MatchString(compact) {
+		return "", fmt.Errorf("%w: malformed postcode area %q", ErrInvalidPostcode, text)
+	}
+	return Area(compact), nil
+}
+
+//ParseDistrict Returns the District of the given text in canonical form. Case and white space are ignored.
+func ParseDistrict(text string) (District, error) {
+	compact := compactCode(text)
+	if !validOutcode(compact) && compact != bfpo {
+		return "", fmt.Errorf("%w: malformed postcode district %q", ErrInvalidPostcode, text)
+	}
+	return District(compact), nil
+}
+
+//ParseSector Returns the Sector of the given text in canonical form, e.g. "RG12 2" for "rg122". Case and white
+//space are ignored.
+func ParseSector(text string) (Sector, error) {
+	compact := compactCode(text)
+	if len(compact) < 3 || !validIncode(compact[:len(compact)-1], compact[len(compact)-1:]) {
+		return "", fmt.Errorf("%w: malformed postcode sector %q", ErrInvalidPostcode, text)
+	}
+	return Sector(compact[:len(compact)-1] + " " + compact[len(compact)-1:]), nil
+}
+
+//ParseUnit Returns the Unit of the given text in canonical form, e.g. "RG12 2PE" for "rg122pe". Case and white
+//space are ignored.
+func ParseUnit(text string) (Unit, error) {
+	compact := compactCode(text)
+	if len(compact) < 5 {
+		return "", fmt.Errorf("%w: malformed postcode unit %q", ErrInvalidPostcode, text)
+	}
+	outcode, incode := compact[:len(compact)-3], compact[len(compact)-3:]
+	if !validIncode(outcode, incode) {
+		return "", fmt.Errorf("%w: malformed postcode unit %q", ErrInvalidPostcode, text)
+	}
+	return Unit(outcode + " " + incode), nil
+}
+
+func (a Area) String() string {
+	return string(a)
+}
+
+//Area returns the area itself
+func (a Area) Area() Area {
+	return a
+}
+
+//Contains reports whether the given level belongs to the area
+func (a Area) Contains(other PostcodeLevel) bool {
+	return a != "" && other != nil && other.Area() == a
+}
+
+//Compare returns -1, 0 or +1 depending on whether a sorts before, equal to or after other
+func (a Area) Compare(other Area) int {
+	return strings.Compare(string(a), string(other))
+}
+
+//Less reports whether a sorts before other
+func (a Area) Less(other Area) bool {
+	return a.Compare(other) < 0
+}
+
+func (a Area) MarshalText() ([]byte, error) {
+	return []byte(a), nil
+}
+
+func (a *Area) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*a = ""
+		return nil
+	}
+	area, err := ParseArea(string(text))
+	if err != nil {
+		return err
+	}
+	*a = area
+	return nil
+}
+
+func (d District) String() string {
+	return string(d)
+}
+
+//Area returns the postcode area of the district, e.g. "RG" for "RG12"
+func (d District) Area() Area {
+	return Area(leadingLetters(string(d)))
+}
+
+//Parent returns the postcode area of the district
+func (d District) Parent() Area {
+	return d.Area()
+}
+
+//Contains reports whether the given level belongs to the district
+func (d District) Contains(other PostcodeLevel) bool {
+	switch level := other.(type) {
+	case District:
+		return d != "" && level == d
+	case Sector:
+		return d != "" && level.District() == d
+	case Unit:
+		return d != "" && level.District() == d
+	}
+	return false
+}
+
+//Compare returns -1, 0 or +1 depending on whether d sorts before, equal to or after other. Districts are ordered
+//naturally, e.g. RG2 before RG12.
+func (d District) Compare(other District) int {
+	return splitDistrict(string(d)).compare(splitDistrict(string(other)))
+}
+
+//Less reports whether d sorts before other
+func (d District) Less(other District) bool {
+	return d.Compare(other) < 0
+}
+
+func (d District) MarshalText() ([]byte, error) {
+	return []byte(d), nil
+}
+
+func (d *District) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*d = ""
+		return nil
+	}
+	district, err := ParseDistrict(string(text))
+	if err != nil {
+		return err
+	}
+	*d = district
+	return nil
+}
+
+func (s Sector) String() string {
+	return string(s)
+}
+
+//Area returns the postcode area of the sector, e.g. "RG" for "RG12 2"
+func (s Sector) Area() Area {
+	return s.District().Area()
+}
+
+//District returns the postcode district of the sector, e.g. "RG12" for "RG12 2"
+func (s Sector) District() District {
+	if i := strings.IndexByte(string(s), ' '); i >= 0 {
+		return District(s[:i])
+	}
+	return ""
+}
+
+//Parent returns the postcode district of the sector
+func (s Sector) Parent() District {
+	return s.District()
+}
+
+//Contains reports whether the given level belongs to the sector
+func (s Sector) Contains(other PostcodeLevel) bool {
+	switch level := other.(type) {
+	case Sector:
+		return s != "" && level == s
+	case Unit:
+		return s != "" && level.Sector() == s
+	}
+	return false
+}
+
+//Compare returns -1, 0 or +1 depending on whether s sorts before, equal to or after other
+func (s Sector) Compare(other Sector) int {
+	if c := s.District().Compare(other.District()); c != 0 {
+		return c
+	}
+	return strings.Compare(string(s), string(other))
+}
+
+//Less reports whether s sorts before other
+func (s Sector) Less(other Sector) bool {
+	return s.Compare(other) < 0
+}
+
+func (s Sector) MarshalText() ([]byte, error) {
+	return []byte(s), nil
+}
+
+func (s *Sector) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*s = ""
+		return nil
+	}
+	sector, err := ParseSector(string(text))
+	if err != nil {
+		return err
+	}
+	*s = sector
+	return nil
+}
+
+func (u Unit) String() string {
+	return string(u)
+}
+
+//Area returns the postcode area of the unit, e.g. "RG" for "RG12 2PE"
+func (u Unit) Area() Area {
+	return u.District().Area()
+}
+
+//District returns the postcode district of the unit, e.g. "RG12" for "RG12 2PE"
+func (u Unit) District() District {
+	if i := strings.IndexByte(string(u), ' '); i >= 0 {
+		return District(u[:i])
+	}
+	return ""
+}
+
+//Sector returns the postcode sector of the unit, e.g. "RG12 2" for "RG12 2PE"
+func (u Unit) Sector() Sector {
+	if i := strings.IndexByte(string(u), ' '); i >= 0 && i+1 < len(u) {
+		return Sector(u[:i+2])
+	}
+	return ""
+}
+
+//Parent returns the postcode sector of the unit
+func (u Unit) Parent() Sector {
+	return u.Sector()
+}
+
+//Contains reports whether the given level is the unit itself
+func (u Unit) Contains(other PostcodeLevel) bool {
+	level, ok := other.(Unit)
+	return ok && u != "" && level == u
+}
+
+//Compare returns -1, 0 or +1 depending on whether u sorts before, equal to or after other
+func (u Unit) Compare(other Unit) int {
+	if c := u.Sector().Compare(other.Sector()); c != 0 {
+		return c
+	}
+	return strings.Compare(string(u), string(other))
+}
+
+//Less reports whether u sorts before other
+func (u Unit) Less(other Unit) bool {
+	return u.Compare(other) < 0
+}
+
+func (u Unit) MarshalText() ([]byte, error) {
+	return []byte(u), nil
+}
+
+func (u *Unit) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*u = ""
+		return nil
+	}
+	unit, err := ParseUnit(string(text))
+	if err != nil {
+		return err
+	}
+	*u = unit
+	return nil
+}
+
+//Unit returns the postcode unit of the postcode, e.g. "RG12 2PE"
+func (p Postcode) Unit() Unit {
+	unit, _ := ParseUnit(p.Postcode)
+	return unit
+}
+
+//Sector returns the postcode sector of the postcode, e.g. "RG12 2"
+func (p Postcode) Sector() Sector {
+	return p.Unit().Sector()
+}
+
+//District returns the postcode district of the postcode, e.g. "RG12"
+func (p Postcode) District() District {
+	return p.Unit().District()
+}
+
+//Area returns the postcode area of the postcode, e.g. "RG"
+func (p Postcode) Area() Area {
+	return p.Unit().Area()
+}
+
+//District returns the postcode district of the outcode, e.g. "RG12"
+func (o OutcodeData) District() District {
+	district, _ := ParseDistrict(o.Outcode)
+	return district
+}
+
+//Area returns the postcode area of the outcode, e.g. "RG"
+func (o OutcodeData) Area() Area {
+	return o.District().Area()
+}
+
+func splitDistrict(district string) districtParts {
+	area := leadingLetters(district)
+	rest := district[len(area):]
+	digits := 0
+	for digits < len(rest) && isDigit(rest[digits]) {
+		digits++
+	}
+	number := -1
+	if digits > 0 {
+		number, _ = strconv.Atoi(rest[:digits])
+	}
+	return districtParts{area: area, number: number, suffix: rest[digits:]}
+}
+
+func (d districtParts) compare(other districtParts) int {
+	if c := strings.Compare(d.area, other.area); c != 0 {
+		return c
+	}
+	switch {
+	case d.number < other.number:
+		return -1
+	case d.number > other.number:
+		return 1
+	}
+	return strings.Compare(d.suffix, other.suffix)
+}
+
+//validOutcode reports whether the outward code follows the Royal Mail format rules, BFPO excepted
+func validOutcode(outcode string) bool {
+	_, ok := nonGeographic[outcode]
+	return ok || outcodePattern.MatchString(outcode)
+}
+
+//validIncode reports whether the inward code, or its leading part down to the sector digit, follows the Royal Mail
+//format rules for the given outward code
+func validIncode(outcode, incode string) bool {
+	if special, ok := nonGeographic[outcode]; ok {
+		return strings.HasPrefix(special, incode)
+	}
+	if !outcodePattern.MatchString(outcode) {
+		return false
+	}
+	if len(incode) == 1 {
+		return isDigit(incode[0])
+	}
+	return incodePattern.MatchString(incode)
+}
+
+func leadingLetters(code string) string {
+	for i := 0; i < len(code); i++ {
+		if !isLetter(code[i]) {
+			return code[:i]
+		}
+	}
+	return code
+}
+
+func compactCode(text string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(text), ""))
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+func isLetter(b byte) bool {
+	return b >= 'A' && b <= 'Z'
+}
@@ -0,0 +1,230 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"testing"
+)
+
+func TestParseLevels(t *testing.T) {
+	tests := []struct {
+		text  string
+		parse func(string) (PostcodeLevel, error)
+		want  string
+	}{
+		{text: "rg", parse: parseArea, want: "RG"},
+		{text: "W", parse: parseArea, want: "W"},
+		{text: "GIR", parse: parseArea, want: "GIR"},
+		{text: "BFPO", parse: parseArea, want: "BFPO"},
+		{text: "QA", parse: parseArea},
+		{text: "RGX", parse: parseArea},
+		{text: "RG1", parse: parseArea},
+		{text: "", parse: parseArea},
+
+		{text: "rg12", parse: parseDistrict, want: "RG12"},
+		{text: "EC1A", parse: parseDistrict, want: "EC1A"},
+		{text: "W1A", parse: parseDistrict, want: "W1A"},
+		{text: "ASCN", parse: parseDistrict, want: "ASCN"},
+		{text: "BFPO", parse: parseDistrict, want: "BFPO"},
+		{text: "RG123", parse: parseDistrict},
+		{text: "RG", parse: parseDistrict},
+		{text: "Q1", parse: parseDistrict},
+		{text: "AB1C", parse: parseDistrict},
+
+		{text: "rg12 2", parse: parseSector, want: "RG12 2"},
+		{text: "rg122", parse: parseSector, want: "RG12 2"},
+		{text: "GIR 0", parse: parseSector, want: "GIR 0"},
+		{text: "GIR 1", parse: parseSector},
+		{text: "RG123 2", parse: parseSector},
+		{text: "RG12 A", parse: parseSector},
+		{text: "BFPO 1", parse: parseSector},
+
+		{text: "rg122pe", parse: parseUnit, want: "RG12 2PE"},
+		{text: " sw1a  1aa ", parse: parseUnit, want: "SW1A 1AA"},
+		{text: "GIR 0AA", parse: parseUnit, want: "GIR 0AA"},
+		{text: "STHL 1ZZ", parse: parseUnit, want: "STHL 1ZZ"},
+		{text: "RG123 2PE", parse: parseUnit},
+		{text: "RG12 2PC", parse: parseUnit},
+		{text: "STHL 1AA", parse: parseUnit},
+		{text: "BFPO 123", parse: parseUnit},
+		{text: "RG12", parse: parseUnit},
+	}
+	for _, test := range tests {
+		level, err := test.parse(test.text)
+		if test.want == "" {
+			if !errors.Is(err, ErrInvalidPostcode) {
+				t.Errorf("parsing %q error = %v, want ErrInvalidPostcode", test.text, err)
+			}
+			continue
+		}
+		if err != nil || level.String() != test.want {
+			t.Errorf("parsing %q = %v, %v, want %s", test.text, level, err, test.want)
+		}
+	}
+}
+
+func parseArea(text string) (PostcodeLevel, error) {
+	return ParseArea(text)
+}
+
+func parseDistrict(text string) (PostcodeLevel, error) {
+	return ParseDistrict(text)
+}
+
+func parseSector(text string) (PostcodeLevel, error) {
+	return ParseSector(text)
+}
+
+func parseUnit(text string) (PostcodeLevel, error) {
+	return ParseUnit(text)
+}
+
+func TestDistrictOrdering(t *testing.T) {
+	districts := []District{"RG12", "RG2", "RG1", "EC1A", "EC1", "E1W", "E1", "E10", "GIR", "RG"}
+	sort.Slice(districts, func(i, j int) bool {
+		return districts[i].Less(districts[j])
+	})
+	want := []District{"E1", "E1W", "E10", "EC1", "EC1A", "GIR", "RG", "RG1", "RG2", "RG12"}
+	for i := range want {
+		if districts[i] != want[i] {
+			t.Fatalf("sorted districts = %v, want %v", districts, want)
+		}
+	}
+
+	tests := []struct {
+		a, b District
+		want int
+	}{
+		{a: "RG2", b: "RG12", want: -1},
+		{a: "RG12", b: "RG2", want: 1},
+		{a: "RG12", b: "RG12", want: 0},
+		{a: "SW1A", b: "SW1", want: 1},
+	}
+	for _, test := range tests {
+		if got := test.a.Compare(test.b); got != test.want {
+			t.Errorf("%s.Compare(%s) = %d, want %d", test.a, test.b, got, test.want)
+		}
+		if got := test.a.Less(test.b); got != (test.want < 0) {
+			t.Errorf("%s.Less(%s) = %v, want %v", test.a, test.b, got, test.want < 0)
+		}
+	}
+}
+
+func TestOrdering(t *testing.T) {
+	tests := []struct {
+		name string
+		less bool
+		got  int
+		want int
+	}{
+		{name: "areas", less: Area("EH").Less("RG"), got: Area("EH").Compare("RG"), want: -1},
+		{name: "equal areas", less: Area("RG").Less("RG"), got: Area("RG").Compare("RG"), want: 0},
+		{name: "sectors of natural districts", less: Sector("RG2 9").Less("RG12 1"), got: Sector("RG2 9").Compare("RG12 1"), want: -1},
+		{name: "sectors of a district", less: Sector("RG12 8").Less("RG12 2"), got: Sector("RG12 8").Compare("RG12 2"), want: 1},
+		{name: "units of natural districts", less: Unit("RG2 0AA").Less("RG12 0AA"), got: Unit("RG2 0AA").Compare("RG12 0AA"), want: -1},
+		{name: "units of a sector", less: Unit("RG12 2PE").Less("RG12 2AA"), got: Unit("RG12 2PE").Compare("RG12 2AA"), want: 1},
+		{name: "equal units", less: Unit("RG12 2PE").Less("RG12 2PE"), got: Unit("RG12 2PE").Compare("RG12 2PE"), want: 0},
+	}
+	for _, test := range tests {
+		if test.got != test.want || test.less != (test.want < 0) {
+			t.Errorf("%s: Compare() = %d, Less() = %v, want %d", test.name, test.got, test.less, test.want)
+		}
+	}
+}
+
+func TestContains(t *testing.T) {
+	tests := []struct {
+		parent PostcodeLevel
+		child  PostcodeLevel
+		want   bool
+	}{
+		{parent: Area("RG"), child: Unit("RG12 2PE"), want: true},
+		{parent: Area("RG"), child: District("RG12"), want: true},
+		{parent: Area("RG"), child: Area("RG"), want: true},
+		{parent: Area("R"), child: District("RG12"), want: false},
+		{parent: Area(""), child: District(""), want: false},
+		{parent: District("RG12"), child: Sector("RG12 2"), want: true},
+		{parent: District("RG12"), child: Unit("RG12 2PE"), want: true},
+		{parent: District("RG1"), child: Unit("RG12 2PE"), want: false},
+		{parent: District("RG12"), child: Area("RG"), want: false},
+		{parent: Sector("RG12 2"), child: Unit("RG12 2PE"), want: true},
+		{parent: Sector("RG12 2"), child: Unit("RG12 3PE"), want: false},
+		{parent: Sector("RG12 2"), child: District("RG12"), want: false},
+		{parent: Unit("RG12 2PE"), child: Unit("RG12 2PE"), want: true},
+		{parent: Unit("RG12 2PE"), child: Sector("RG12 2"), want: false},
+	}
+	for _, test := range tests {
+		var got bool
+		switch parent := test.parent.(type) {
+		case Area:
+			got = parent.Contains(test.child)
+		case District:
+			got = parent.Contains(test.child)
+		case Sector:
+			got = parent.Contains(test.child)
+		case Unit:
+			got = parent.Contains(test.child)
+		}
+		if got != test.want {
+			t.Errorf("%T(%q).Contains(%T(%q)) = %v, want %v", test.parent, test.parent, test.child, test.child, got, test.want)
+		}
+	}
+}
+
+func TestParents(t *testing.T) {
+	unit := Unit("RG12 2PE")
+	if unit.Parent() != "RG12 2" || unit.Parent().Parent() != "RG12" || unit.Parent().Parent().Parent() != "RG" {
+		t.Errorf("parents of %s = %s, %s, %s", unit, unit.Parent(), unit.Parent().Parent(), unit.Parent().Parent().Parent())
+	}
+	if area := Unit("GIR 0AA").Area(); area != "GIR" {
+		t.Errorf("Area() of GIR 0AA = %s, want GIR", area)
+	}
+
+	postcode := Postcode{Postcode: "rg122pe"}
+	if postcode.Unit() != "RG12 2PE" || postcode.District() != "RG12" || postcode.Area() != "RG" {
+		t.Errorf("Postcode levels = %s, %s, %s", postcode.Unit(), postcode.District(), postcode.Area())
+	}
+	if invalid := (Postcode{Postcode: "RG123 2PE"}); invalid.Unit() != "" || invalid.Area() != "" {
+		t.Errorf("levels of an invalid postcode = %q, %q, want none", invalid.Unit(), invalid.Area())
+	}
+	if district := (OutcodeData{Outcode: "rg12"}).District(); district != "RG12" {
+		t.Errorf("OutcodeData.District() = %s, want RG12", district)
+	}
+}
+
+func TestUnmarshalText(t *testing.T) {
+	var levels struct {
+		Area     Area     `json:"area"`
+		District District `json:"district"`
+		Sector   Sector   `json:"sector"`
+		Unit     Unit     `json:"unit"`
+	}
+	if err := json.Unmarshal([]byte(`{"area":"rg","district":"rg12","sector":"rg122","unit":"rg122pe"}`), &levels); err != nil {
+		t.Fatal(err)
+	}
+	if levels.Area != "RG" || levels.District != "RG12" || levels.Sector != "RG12 2" || levels.Unit != "RG12 2PE" {
+		t.Errorf("unmarshalled levels = %+v", levels)
+	}
+
+	encoded, err := json.Marshal(levels)
+	if err != nil || string(encoded) != `{"area":"RG","district":"RG12","sector":"RG12 2","unit":"RG12 2PE"}` {
+		t.Errorf("marshalled levels = %s, %v", encoded, err)
+	}
+
+	if err := json.Unmarshal([]byte(`{"area":"","district":"","sector":"","unit":""}`), &levels); err != nil || levels.Unit != "" || levels.Area != "" {
+		t.Errorf("unmarshalling empty levels = %+v, %v, want empty", levels, err)
+	}
+
+	invalid := []string{
+		`{"area":"RG1"}`,
+		`{"district":"RG123"}`,
+		`{"sector":"RG12 A"}`,
+		`{"unit":"RG123 2PE"}`,
+	}
+	for _, text := range invalid {
+		if err := json.Unmarshal([]byte(text), &levels); !errors.Is(err, ErrInvalidPostcode) {
+			t.Errorf("unmarshalling %s error = %v, want ErrInvalidPostcode", text, err)
+		}
+	}
+}
@@ -19,5 +19,8 @@ type (
 		AdminCounty   []string `json:"adminCounty,omitempty"`
 		AdminWard     []string `json:"adminWard,omitempty"`
 		Country       []string `json:"country,omitempty"`
+
+		//Fallback is set when the outcode was served by the local dataset of a failover client
+		Fallback *Fallback `json:"fallback,omitempty"`
 	}
 )
@@ -63,5 +63,8 @@ type (
 		Nuts                      string  `json:"nuts,omitempty"`
 		Distance                  float64 `json:"distance,omitempty"`
 		Codes                     Codes   `json:"codes,omitempty"`
+
+		//Fallback is set when the postcode was served by the local dataset of a failover client
+		Fallback *Fallback `json:"fallback,omitempty"`
 	}
 )
@@ -14,5 +14,8 @@ type (
 		MonthTerminated int64   `json:"month_terminated"`
 		Longitude       float64 `json:"longitude"`
 		Latitude        float64 `json:"latitude"`
+
+		//Fallback is set when the postcode was served by the local dataset of a failover client
+		Fallback *Fallback `json:"fallback,omitempty"`
 	}
 )
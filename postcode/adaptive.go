@@ -0,0 +1,118 @@
+package postcode
+
+import (
+	"context"
+	"github.com/razorcorp/postcode-sdk-go/model"
+	"github.com/razorcorp/postcode-sdk-go/postcode/internal"
+)
+
+const (
+	//StrategyRadius the postcodes were found by reverse geocoding within a radius of up to 2,000m
+	StrategyRadius Strategy = "radius"
+
+	//StrategyWideSearch the postcodes were found by a wide search of up to 20km, returning up to 10 postcodes
+	StrategyWideSearch Strategy = "widesearch"
+
+	//StrategyOutcode no postcode was found nearby; the nearest outcodes were found instead
+	StrategyOutcode Strategy = "outcode"
+)
+
+const (
+	//wideSearchRadius radius in metres covered by a wide search
+	wideSearchRadius = 20000
+
+	//wideSearchLimit largest number of postcodes returned by a wide search
+	wideSearchLimit = 10
+
+	//outcodeSearchRadius largest radius in metres accepted by outcode reverse geocoding
+	outcodeSearchRadius = 25000
+)
+
+//escalationRadii radii in metres tried in turn by ReverseGeocodingAdaptive
+var escalationRadii = []int64{100, 250, 500, 1000, 2000}
+
+type (
+	//Strategy search that produced the result of ReverseGeocodingAdaptive
+	Strategy string
+
+	//AdaptiveResult is the outcome of ReverseGeocodingAdaptive
+	AdaptiveResult struct {
+		//Postcodes nearest postcodes, nearest first. Empty when Strategy is StrategyOutcode
+		Postcodes []model.Postcode
+
+		//Outcodes nearest outcodes, nearest first. Only set when Strategy is StrategyOutcode
+		Outcodes []model.OutcodeData
+
+		//Strategy search that produced the result
+		Strategy Strategy
+
+		//Radius effective search radius in metres
+		Radius int64
+	}
+)
+
+//ReverseGeocodingAdaptive Returns the nearest postcodes for a given longitude and latitude without guessing a radius.
+//
+//The radius starts at 100m and widens step by step up to 2,000m until n postcodes are found. If none is found within
+//2,000m a wide search of up to 20km returns up to 10 postcodes, and failing that the nearest outcodes within 25km are
+//returned as a coarse answer. The result reports the strategy and the radius that produced it. The Limit, Radius and
+//WideSearch of the given geocode are ignored; n needs to be between 1 and 100.
+func (c *Client) ReverseGeocodingAdaptive(geocode Geocode, n int64) (*AdaptiveResult, *model.ResponseError) {
+	return c.ReverseGeocodingAdaptiveContext(context.Background(), geocode, n)
+}
+
+//ReverseGeocodingAdaptiveContext is like ReverseGeocodingAdaptive but carries the given context through to the
+//requests
+func (c *Client) ReverseGeocodingAdaptiveContext(ctx context.Context, geocode Geocode, n int64) (result *AdaptiveResult, err *model.ResponseError) {
+	ctx, span := c.startOperation(ctx, "ReverseGeocodingAdaptive", 0)
+	defer func() { endOperation(span, result, err) }()
+
+	if err := geocode.validate(); err != nil {
+		return nil, err
+	}
+	if n < 1 || n > maxBulkSize {
+		return nil, internal.ValidationError("Number of postcodes must be between 1 and 100")
+	}
+
+	point := Geocode{Latitude: geocode.Latitude, Longitude: geocode.Longitude, Limit: n}
+
+	var found []model.Postcode
+	var radius int64
+	for _, radius = range escalationRadii {
+		point.Radius = radius
+		data, err := c.ReverseGeocodingContext(ctx, point)
+		if err != nil {
+			return nil, err
+		}
+		found = data
+		if int64(len(found)) >= n {
+			break
+		}
+	}
+	if len(found) > 0 {
+		return &AdaptiveResult{Postcodes: found, Strategy: StrategyRadius, Radius: radius}, nil
+	}
+
+	wide := Geocode{Latitude: geocode.Latitude, Longitude: geocode.Longitude, WideSearch: true}
+	if n < wideSearchLimit {
+		wide.Limit = n
+	}
+	found, err = c.ReverseGeocodingContext(ctx, wide)
+	if err != nil {
+		return nil, err
+	}
+	if len(found) > 0 {
+		return &AdaptiveResult{Postcodes: found, Strategy: StrategyWideSearch, Radius: wideSearchRadius}, nil
+	}
+
+	outcodes, err := c.OutcodeReverseGeocodingContext(ctx, Geocode{
+		Latitude:  geocode.Latitude,
+		Longitude: geocode.Longitude,
+		Limit:     n,
+		Radius:    outcodeSearchRadius,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &AdaptiveResult{Outcodes: outcodes, Strategy: StrategyOutcode, Radius: outcodeSearchRadius}, nil
+}
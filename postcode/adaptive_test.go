@@ -0,0 +1,169 @@
+package postcode_test
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/razorcorp/postcode-sdk-go/model"
+	"github.com/razorcorp/postcode-sdk-go/postcode"
+	"github.com/razorcorp/postcode-sdk-go/postcode/postcodetest"
+)
+
+//origin point searched by the adaptive reverse geocoding tests
+var origin = postcode.Geocode{Latitude: 51.5, Longitude: -1.0}
+
+//northOf returns a postcode the given number of metres north of the origin
+func northOf(code string, metres float64) model.Postcode {
+	return model.Postcode{Postcode: code, Quality: 1, Latitude: origin.Latitude + metres/111195, Longitude: origin.Longitude}
+}
+
+//recordSearches returns a middleware recording the reverse geocoding searches, e.g. "postcodes radius=250" or
+//"postcodes widesearch limit=3"
+func recordSearches(searches *[]string) postcode.Middleware {
+	var mu sync.Mutex
+	return func(next postcode.Doer) postcode.Doer {
+		return postcode.DoerFunc(func(req *http.Request) (*http.Response, error) {
+			query := req.URL.Query()
+			search := strings.Trim(req.URL.Path, "/")
+			if query.Get("widesearch") == "true" {
+				search += " widesearch limit=" + query.Get("limit")
+			} else {
+				search += " radius=" + query.Get("radius")
+			}
+			mu.Lock()
+			*searches = append(*searches, search)
+			mu.Unlock()
+			return next.Do(req)
+		})
+	}
+}
+
+func TestReverseGeocodingAdaptive(t *testing.T) {
+	radii := []string{"postcodes radius=100", "postcodes radius=250", "postcodes radius=500", "postcodes radius=1000", "postcodes radius=2000"}
+	for _, test := range []struct {
+		name      string
+		postcodes []model.Postcode
+		n         int64
+		strategy  postcode.Strategy
+		radius    int64
+		found     []string
+		searches  []string
+	}{
+		{
+			name:      "first radius",
+			postcodes: []model.Postcode{northOf("OX1 1AA", 50), northOf("OX1 1AB", 700)},
+			n:         1,
+			strategy:  postcode.StrategyRadius,
+			radius:    100,
+			found:     []string{"OX1 1AA"},
+			searches:  radii[:1],
+		},
+		{
+			name:      "widens until found",
+			postcodes: []model.Postcode{northOf("OX1 1AA", 180), northOf("OX1 1AB", 700)},
+			n:         1,
+			strategy:  postcode.StrategyRadius,
+			radius:    250,
+			found:     []string{"OX1 1AA"},
+			searches:  radii[:2],
+		},
+		{
+			name:      "widens until n found",
+			postcodes: []model.Postcode{northOf("OX1 1AA", 180), northOf("OX1 1AB", 700)},
+			n:         2,
+			strategy:  postcode.StrategyRadius,
+			radius:    1000,
+			found:     []string{"OX1 1AA", "OX1 1AB"},
+			searches:  radii[:4],
+		},
+		{
+			name:      "fewer than n within 2000m",
+			postcodes: []model.Postcode{northOf("OX1 1AA", 180), northOf("OX1 1AB", 1500), northOf("OX1 1AD", 5000)},
+			n:         5,
+			strategy:  postcode.StrategyRadius,
+			radius:    2000,
+			found:     []string{"OX1 1AA", "OX1 1AB"},
+			searches:  radii,
+		},
+		{
+			name:      "wide search",
+			postcodes: []model.Postcode{northOf("OX2 1AA", 10000), northOf("OX2 1AB", 12000)},
+			n:         3,
+			strategy:  postcode.StrategyWideSearch,
+			radius:    20000,
+			found:     []string{"OX2 1AA", "OX2 1AB"},
+			searches:  append(append([]string(nil), radii...), "postcodes widesearch limit=3"),
+		},
+		{
+			name:      "outcode",
+			postcodes: []model.Postcode{northOf("OX3 1AA", 22000)},
+			n:         3,
+			strategy:  postcode.StrategyOutcode,
+			radius:    25000,
+			found:     []string{"OX3"},
+			searches:  append(append([]string(nil), radii...), "postcodes widesearch limit=3", "outcodes radius=25000"),
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fake := postcodetest.NewServer(postcodetest.Dataset{Postcodes: test.postcodes})
+			defer fake.Close()
+			var searches []string
+			client := fake.Client(postcode.WithMiddleware(recordSearches(&searches)))
+
+			result, err := client.ReverseGeocodingAdaptive(origin, test.n)
+			if err != nil {
+				t.Fatalf("ReverseGeocodingAdaptive() error = %v", err)
+			}
+			if result.Strategy != test.strategy || result.Radius != test.radius {
+				t.Errorf("ReverseGeocodingAdaptive() = %s within %dm, want %s within %dm", result.Strategy, result.Radius, test.strategy, test.radius)
+			}
+
+			var found []string
+			for _, p := range result.Postcodes {
+				found = append(found, p.Postcode)
+			}
+			for _, o := range result.Outcodes {
+				found = append(found, o.Outcode)
+			}
+			if !reflect.DeepEqual(found, test.found) {
+				t.Errorf("ReverseGeocodingAdaptive() found %v, want %v", found, test.found)
+			}
+			if !reflect.DeepEqual(searches, test.searches) {
+				t.Errorf("searches = %v, want %v", searches, test.searches)
+			}
+		})
+	}
+}
+
+func TestReverseGeocodingAdaptiveErrors(t *testing.T) {
+	fake := newFake(t)
+	client := fake.Client()
+
+	for _, test := range []struct {
+		geocode postcode.Geocode
+		n       int64
+	}{
+		{postcode.Geocode{}, 1},
+		{origin, 0},
+		{origin, 101},
+	} {
+		if _, err := client.ReverseGeocodingAdaptive(test.geocode, test.n); !errors.Is(err, model.ErrValidation) {
+			t.Errorf("ReverseGeocodingAdaptive(%+v, %d) error = %v, want a validation error", test.geocode, test.n, err)
+		}
+	}
+	if calls := fake.Calls("", ""); calls != 0 {
+		t.Errorf("calls = %d, want invalid searches refused locally", calls)
+	}
+
+	fake.Inject(postcodetest.Fault{Kind: postcodetest.FaultStatus, After: 1, Status: http.StatusInternalServerError})
+	if _, err := client.ReverseGeocodingAdaptive(origin, 1); !errors.Is(err, model.ErrServer) {
+		t.Errorf("ReverseGeocodingAdaptive() error = %v, want the failure of the second search", err)
+	}
+	if calls := fake.Calls(http.MethodGet, "postcodes"); calls != 2 {
+		t.Errorf("searches = %d, want the escalation stopped by the failure", calls)
+	}
+}
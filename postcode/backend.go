@@ -0,0 +1,93 @@
+package postcode
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/razorcorp/postcode-sdk-go/model"
+	"github.com/razorcorp/postcode-sdk-go/postcode/internal"
+)
+
+//defaultLimit number of results of Autocomplete and Query without a limit, as for the API
+const defaultLimit = 10
+
+type (
+	//Backend answers the postcode operations of a Client in place of the postcodes.io API, e.g. from a local copy of
+	//the ONS Postcode Directory, see the onspd package. Postcodes and outcodes are given in their canonical form, e.g.
+	//"RG12 2PE", after the Client validated them. Errors follow the API, e.g. a 404 "Postcode not found" for an
+	//unknown postcode. Implementations must be safe for concurrent use.
+	Backend interface {
+		//Lookup returns the postcode
+		Lookup(ctx context.Context, postcode string) (*model.Postcode, *model.ResponseError)
+
+		//BulkLookup returns a result per postcode in order, with a zero Postcode for the unknown ones
+		BulkLookup(ctx context.Context, postcodes []string) ([]model.Postcodes, *model.ResponseError)
+
+		//Validation reports whether the postcode exists
+		Validation(ctx context.Context, postcode string) (bool, *model.ResponseError)
+
+		//Autocomplete returns up to limit postcodes starting with the given partial postcode
+		Autocomplete(ctx context.Context, postcode string, limit int) ([]string, *model.ResponseError)
+
+		//Query returns up to limit postcodes prefix matching the given query
+		Query(ctx context.Context, query string, limit int) ([]model.Postcode, *model.ResponseError)
+
+		//OutcodeLookup returns the outcode
+		OutcodeLookup(ctx context.Context, outcode string) (*model.OutcodeData, *model.ResponseError)
+
+		//TerminatedPostcodeLookup returns the terminated postcode
+		TerminatedPostcodeLookup(ctx context.Context, postcode string) (*model.TerminatedPostcode, *model.ResponseError)
+	}
+)
+
+//WithBackend answers Lookup, BulkLookup, Validation, Autocomplete, Query, OutcodeLookup and TerminatedPostcodeLookup
+//from the given backend instead of the API. Validation and caching of the Client still apply, batching by
+//WithBatching does not; the other operations keep using the API.
+func WithBackend(backend Backend) Option {
+	return func(c *Client) {
+		c.backend = backend
+	}
+}
+
+//limitOrDefault returns the given limit, or the default limit of the API when nil
+func limitOrDefault(limit *int64) int {
+	if limit == nil || *limit <= 0 {
+		return defaultLimit
+	}
+	return int(*limit)
+}
+
+//filterPostcodes keeps only the given attributes of the postcodes answered by a backend, and their fallback mark, as
+//the filter parameter of the API does
+func filterPostcodes(results []model.Postcodes, filters []string) ([]model.Postcodes, *model.ResponseError) {
+	if len(filters) == 0 {
+		return results, nil
+	}
+
+	keep := map[string]bool{"fallback": true}
+	for _, filter := range filters {
+		keep[filter] = true
+	}
+	for i := range results {
+		encoded, err := json.Marshal(results[i].Postcode)
+		if err != nil {
+			return nil, internal.ResponseDecodeError(err)
+		}
+		var attributes map[string]json.RawMessage
+		if err := json.Unmarshal(encoded, &attributes); err != nil {
+			return nil, internal.ResponseDecodeError(err)
+		}
+		for attribute := range attributes {
+			if !keep[attribute] {
+				delete(attributes, attribute)
+			}
+		}
+		if encoded, err = json.Marshal(attributes); err != nil {
+			return nil, internal.ResponseDecodeError(err)
+		}
+		results[i].Postcode = model.Postcode{}
+		if err := json.Unmarshal(encoded, &results[i].Postcode); err != nil {
+			return nil, internal.ResponseDecodeError(err)
+		}
+	}
+	return results, nil
+}
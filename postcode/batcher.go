@@ -0,0 +1,177 @@
+package postcode
+
+import (
+	"context"
+	"github.com/razorcorp/postcode-sdk-go/model"
+	"github.com/razorcorp/postcode-sdk-go/postcode/internal"
+	"sync"
+	"time"
+)
+
+//defaultBatchWindow time a batch of Lookup calls waits for further calls before it is sent
+const defaultBatchWindow = 10 * time.Millisecond
+
+type (
+	//batcher collects concurrent Lookup calls into bulk lookups. Calls for a postcode already queued or in flight wait
+	//for the same result.
+	batcher struct {
+		client *Client
+		window time.Duration
+		size   int
+
+		mu      sync.Mutex
+		pending map[string]*batchCall
+		queue   *batch
+	}
+
+	//batch postcodes sent by a single bulk lookup. The lookup is cancelled once every caller waiting for one of its
+	//postcodes gave up.
+	batch struct {
+		ctx       context.Context
+		cancel    context.CancelFunc
+		postcodes []string
+		calls     []*batchCall
+		waiters   int
+		timer     *time.Timer
+	}
+
+	//batchCall result of a postcode shared by every caller waiting for it
+	batchCall struct {
+		batch    *batch
+		done     chan struct{}
+		postcode *model.Postcode
+		err      *model.ResponseError
+	}
+)
+
+//WithBatching collects concurrent Lookup calls into bulk lookups of up to maxBatch postcodes, each sent once full or
+//once the given window passed since its first postcode was queued. Concurrent calls for the same postcode are
+//coalesced into a single query. The bulk lookups are retried like the Lookup calls they serve, regardless of
+//RetryPolicy.RetryPost. Lookup calls answered by a Backend are not batched, except those a WithFailover client sends
+//to postcodes.io.
+//
+//A window of 0 defaults to 10ms; a maxBatch of 0, or over 100, to 100.
+func WithBatching(window time.Duration, maxBatch int) Option {
+	return func(c *Client) {
+		if window <= 0 {
+			window = defaultBatchWindow
+		}
+		if maxBatch <= 0 || maxBatch > maxBulkSize {
+			maxBatch = maxBulkSize
+		}
+		c.batcher = &batcher{
+			window:  window,
+			size:    maxBatch,
+			pending: make(map[string]*batchCall),
+		}
+	}
+}
+
+//lookup queues the given canonical postcode and waits for its result or for the context to be done
+func (b *batcher) lookup(ctx context.Context, postcode string) (*model.Postcode, *model.ResponseError) {
+	b.mu.Lock()
+	call, ok := b.pending[postcode]
+	if !ok {
+		if b.queue == nil {
+			b.queue = newBatch(ctx)
+			b.queue.timer = time.AfterFunc(b.window, b.flush)
+		}
+		call = &batchCall{batch: b.queue, done: make(chan struct{})}
+		b.pending[postcode] = call
+		b.queue.postcodes = append(b.queue.postcodes, postcode)
+		b.queue.calls = append(b.queue.calls, call)
+		if len(b.queue.postcodes) >= b.size {
+			go b.dispatch(b.take())
+		}
+	}
+	call.batch.waiters++
+	b.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.postcode, call.err
+	case <-ctx.Done():
+		b.leave(call)
+		return nil, internal.TransportError(ctx.Err())
+	}
+}
+
+//leave removes a caller that gave up from the batch of the call. Once no caller is left the batch is cancelled and
+//its postcodes are no longer joined by new calls.
+func (b *batcher) leave(call *batchCall) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	batch := call.batch
+	batch.waiters--
+	if batch.waiters > 0 {
+		return
+	}
+	batch.cancel()
+	b.forget(batch)
+	if b.queue == batch {
+		// answered as cancelled without a request, so that new calls start a new batch
+		go b.dispatch(b.take())
+	}
+}
+
+//flush sends the queued postcodes once the window passed
+func (b *batcher) flush() {
+	b.mu.Lock()
+	batch := b.take()
+	b.mu.Unlock()
+
+	if batch != nil {
+		b.dispatch(batch)
+	}
+}
+
+//take empties the queue and returns its batch, or nil. The caller must hold the lock.
+func (b *batcher) take() *batch {
+	batch := b.queue
+	if batch != nil {
+		batch.timer.Stop()
+	}
+	b.queue = nil
+	return batch
+}
+
+//forget removes the calls of the batch from the pending calls. The caller must hold the lock.
+func (b *batcher) forget(batch *batch) {
+	for i, postcode := range batch.postcodes {
+		if b.pending[postcode] == batch.calls[i] {
+			delete(b.pending, postcode)
+		}
+	}
+}
+
+//dispatch looks the batch up and hands each result to its callers. The batch carries the values of the context of its
+//first caller but is not bound to its cancellation, as it serves all of them; it is cancelled once all of them gave up.
+func (b *batcher) dispatch(batch *batch) {
+	defer batch.cancel()
+
+	var data []model.Postcodes
+	var err *model.ResponseError
+	if ctxErr := batch.ctx.Err(); ctxErr != nil {
+		err = internal.TransportError(ctxErr)
+	} else {
+		data, err = b.client.apiBulkLookup(withIdempotent(batch.ctx), Postcodes{Postcodes: batch.postcodes}, nil)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.forget(batch)
+	for i, postcode := range batch.postcodes {
+		result := lookupResult(postcode, data, i, err, nil)
+		call := batch.calls[i]
+		call.postcode, call.err = result.Postcode, result.Error
+		close(call.done)
+	}
+}
+
+//newBatch returns an empty batch carrying the values of the given context of its first caller, e.g. its tracing span
+//or logging attributes, but not its cancellation or deadline
+func newBatch(ctx context.Context) *batch {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	return &batch{ctx: ctx, cancel: cancel}
+}
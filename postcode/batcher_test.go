@@ -0,0 +1,248 @@
+package postcode
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/razorcorp/postcode-sdk-go/model"
+)
+
+//callerKey context key of a value set by the callers of the batching tests
+type callerKey struct{}
+
+//bulkServer answers bulk lookups with every queried postcode found, after handing each request to before
+func bulkServer(t *testing.T, before func(w http.ResponseWriter, r *http.Request, call int32) bool) (*httptest.Server, *int32) {
+	t.Helper()
+	calls := new(int32)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("%s %s, want bulk lookups only", r.Method, r.URL.Path)
+		}
+		call := atomic.AddInt32(calls, 1)
+		if before != nil && !before(w, r, call) {
+			return
+		}
+
+		var request struct {
+			Postcodes []string `json:"postcodes"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decoding bulk request: %v", err)
+		}
+		results := make([]model.Postcodes, len(request.Postcodes))
+		for i, postcode := range request.Postcodes {
+			results[i] = model.Postcodes{Query: postcode, Postcode: model.Postcode{Postcode: postcode}}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"status": 200, "result": results})
+	}))
+	t.Cleanup(server.Close)
+	return server, calls
+}
+
+func TestBatchingCoalescesLookups(t *testing.T) {
+	server, calls := bulkServer(t, nil)
+	client := NewClient(WithBaseURL(server.URL), WithBatching(50*time.Millisecond, 100))
+
+	postcodes := []string{"RG1 1AF", "rg11af", "RG1 1AZ", "RG12 2PE", "RG1 1AZ"}
+	var wg sync.WaitGroup
+	for _, postcode := range postcodes {
+		wg.Add(1)
+		go func(postcode string) {
+			defer wg.Done()
+			data, err := client.Lookup(postcode)
+			if err != nil || data.Postcode != normalise(postcode) {
+				t.Errorf("Lookup(%q) = %v, %v", postcode, data, err)
+			}
+		}(postcode)
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("bulk lookups = %d, want 1", got)
+	}
+}
+
+func TestBatchingFullBatch(t *testing.T) {
+	server, calls := bulkServer(t, nil)
+	client := NewClient(WithBaseURL(server.URL), WithBatching(time.Hour, 2))
+
+	var wg sync.WaitGroup
+	for _, postcode := range []string{"RG1 1AF", "RG1 1AZ", "RG12 2PE", "RG1 2AG"} {
+		wg.Add(1)
+		go func(postcode string) {
+			defer wg.Done()
+			if _, err := client.Lookup(postcode); err != nil {
+				t.Errorf("Lookup(%q) error = %v", postcode, err)
+			}
+		}(postcode)
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Errorf("bulk lookups = %d, want 2", got)
+	}
+}
+
+func TestBatchingReadsCacheOnce(t *testing.T) {
+	server, calls := bulkServer(t, nil)
+	cache := NewMemoryCache(10)
+	client := NewClient(WithBaseURL(server.URL), WithBatching(time.Millisecond, 100), WithCache(cache, DefaultCacheTTL))
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Lookup("RG1 1AF"); err != nil {
+			t.Fatalf("Lookup() error = %v", err)
+		}
+	}
+
+	if stats := cache.Stats(); stats.Misses != 1 || stats.Hits != 1 || stats.Entries != 1 {
+		t.Errorf("Stats() = %+v, want 1 miss, 1 hit and 1 entry", stats)
+	}
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("bulk lookups = %d, want 1", got)
+	}
+}
+
+func TestBatchingCancelsAbandonedBatch(t *testing.T) {
+	abandoned := make(chan struct{})
+	server, calls := bulkServer(t, func(w http.ResponseWriter, r *http.Request, call int32) bool {
+		if call > 1 {
+			return true
+		}
+		// the server notices the client going away only once the request body was read
+		io.Copy(io.Discard, r.Body)
+		select {
+		case <-r.Context().Done():
+			close(abandoned)
+		case <-time.After(10 * time.Second):
+			t.Error("abandoned bulk lookup was not cancelled")
+		}
+		return false
+	})
+	client := NewClient(WithBaseURL(server.URL), WithBatching(time.Millisecond, 100))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := client.LookupContext(ctx, "RG1 1AF"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("LookupContext() error = %v, want deadline exceeded", err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	data, err := client.LookupContext(ctx, "RG1 1AF")
+	if err != nil || data.Postcode != "RG1 1AF" {
+		t.Fatalf("LookupContext() after an abandoned batch = %v, %v, want RG1 1AF", data, err)
+	}
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Errorf("bulk lookups = %d, want 2", got)
+	}
+
+	select {
+	case <-abandoned:
+	case <-time.After(5 * time.Second):
+		t.Error("abandoned bulk lookup was not cancelled")
+	}
+}
+
+func TestBatchingKeepsBatchWhileWaited(t *testing.T) {
+	release := make(chan struct{})
+	server, calls := bulkServer(t, func(w http.ResponseWriter, r *http.Request, call int32) bool {
+		<-release
+		return true
+	})
+	client := NewClient(WithBaseURL(server.URL), WithBatching(10*time.Millisecond, 100))
+
+	result := make(chan *model.ResponseError)
+	go func() {
+		_, err := client.Lookup("RG1 1AF")
+		result <- err
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.LookupContext(ctx, "RG1 1AF"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("LookupContext() error = %v, want deadline exceeded", err)
+	}
+	close(release)
+
+	if err := <-result; err != nil {
+		t.Errorf("Lookup() of the remaining caller error = %v", err)
+	}
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("bulk lookups = %d, want 1", got)
+	}
+}
+
+func TestBatchingCarriesCallerValues(t *testing.T) {
+	release := make(chan struct{})
+	server, calls := bulkServer(t, func(w http.ResponseWriter, r *http.Request, call int32) bool {
+		<-release
+		return true
+	})
+	seen := make(chan interface{}, 1)
+	recordValue := func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			seen <- req.Context().Value(callerKey{})
+			return next.Do(req)
+		})
+	}
+	client := NewClient(WithBaseURL(server.URL), WithBatching(20*time.Millisecond, 100), WithMiddleware(recordValue))
+
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), callerKey{}, "first"), 50*time.Millisecond)
+	defer cancel()
+	first := make(chan *model.ResponseError)
+	go func() {
+		_, err := client.LookupContext(ctx, "RG1 1AF")
+		first <- err
+	}()
+	for queued := false; !queued; time.Sleep(time.Millisecond) {
+		client.batcher.mu.Lock()
+		_, queued = client.batcher.pending["RG1 1AF"]
+		client.batcher.mu.Unlock()
+	}
+
+	second := make(chan *model.ResponseError)
+	go func() {
+		_, err := client.Lookup("RG1 1AF")
+		second <- err
+	}()
+
+	if err := <-first; !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("LookupContext() error = %v, want deadline exceeded", err)
+	}
+	close(release)
+	if err := <-second; err != nil {
+		t.Errorf("Lookup() of the remaining caller error = %v, want the batch to outlive the deadline of the first", err)
+	}
+	if value := <-seen; value != "first" {
+		t.Errorf("request context value = %v, want the value of the first caller", value)
+	}
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("bulk lookups = %d, want 1", got)
+	}
+}
+
+func TestBatchingRetries(t *testing.T) {
+	server, calls := bulkServer(t, func(w http.ResponseWriter, r *http.Request, call int32) bool {
+		if call == 1 {
+			status(http.StatusServiceUnavailable, "")(w)
+			return false
+		}
+		return true
+	})
+	client := NewClient(WithBaseURL(server.URL), WithBatching(time.Millisecond, 100), WithRetryPolicy(testPolicy))
+
+	if data, err := client.Lookup("RG1 1AF"); err != nil || data.Postcode != "RG1 1AF" {
+		t.Fatalf("Lookup() = %v, %v, want RG1 1AF", data, err)
+	}
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Errorf("bulk lookups = %d, want 2", got)
+	}
+}
@@ -0,0 +1,262 @@
+package postcode
+
+import (
+	"context"
+	"errors"
+	"github.com/razorcorp/postcode-sdk-go/model"
+	"github.com/razorcorp/postcode-sdk-go/postcode/internal"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+//maxBulkSize maximum number of items accepted by the bulk endpoints
+const maxBulkSize = 100
+
+//errMissingResult the bulk response holds fewer results than queries
+var errMissingResult = errors.New("missing result for query")
+
+//defaultConcurrency number of concurrent bulk requests sent by the chunked operations
+const defaultConcurrency = 4
+
+type (
+	//LookupResult is the outcome of looking up a single postcode of a chunked bulk lookup
+	LookupResult struct {
+		//Query postcode as given by the caller
+		Query string
+
+		//Postcode matching postcode data. nil when the postcode is not found or the lookup failed
+		Postcode *model.Postcode
+
+		//Error reason the postcode could not be looked up
+		Error *model.ResponseError
+	}
+
+	//GeocodeResult is the outcome of reverse geocoding a single geolocation of a chunked bulk reverse geocoding
+	GeocodeResult struct {
+		//Index position of the geolocation in the input
+		Index int
+
+		//Query geolocation as given by the caller
+		Query Geocode
+
+		//Result postcodes matching the geolocation
+		Result model.Geocodes
+
+		//Error reason the geolocation could not be reverse geocoded
+		Error *model.ResponseError
+	}
+)
+
+//WithConcurrency sets the number of concurrent bulk requests sent by the chunked operations, e.g. LookupAll
+func WithConcurrency(concurrency int) Option {
+	return func(c *Client) {
+		c.concurrency = concurrency
+	}
+}
+
+//LookupAll Looks up any number of postcodes by splitting them into bulk lookups of up to 100 postcodes.
+//
+//Returns a result for each given postcode in the same order. Postcodes that are not found or belong to a failed
+//bulk lookup carry the error of their result instead of failing the whole run.
+func (c *Client) LookupAll(postcodes []string, filters []string) []LookupResult {
+	return c.LookupAllContext(context.Background(), postcodes, filters)
+}
+
+//LookupAllContext is like LookupAll but carries the given context through to the requests
+func (c *Client) LookupAllContext(ctx context.Context, postcodes []string, filters []string) []LookupResult {
+	ctx, span := c.startOperation(ctx, "LookupAll", len(postcodes))
+	results := make([]LookupResult, len(postcodes))
+	defer func() { endStreamOperation(span, len(results)) }()
+
+	ctx, skipped := withUnanswered(ctx)
+	c.chunked(ctx, len(postcodes), func(start, end int) {
+		batch := postcodes[start:end]
+		data, err := c.BulkLookupContext(ctx, Postcodes{Postcodes: batch}, filters)
+		for i, postcode := range batch {
+			results[start+i] = lookupResult(postcode, data, i, err, skipped)
+		}
+	})
+	return results
+}
+
+//BulkReverseGeocodingAll Reverse geocodes any number of geolocations by splitting them into bulk reverse geocoding
+//requests of up to 100 geolocations.
+//
+//Returns a result for each given geolocation in the same order. Invalid geolocations and geolocations belonging to a
+//failed request carry the error of their result instead of failing the whole run.
+func (c *Client) BulkReverseGeocodingAll(geocodes []Geocode, filters []string) []GeocodeResult {
+	return c.BulkReverseGeocodingAllContext(context.Background(), geocodes, filters)
+}
+
+//BulkReverseGeocodingAllContext is like BulkReverseGeocodingAll but carries the given context through to the requests
+func (c *Client) BulkReverseGeocodingAllContext(ctx context.Context, geocodes []Geocode, filters []string) []GeocodeResult {
+	ctx, span := c.startOperation(ctx, "BulkReverseGeocodingAll", len(geocodes))
+	results := make([]GeocodeResult, len(geocodes))
+	defer func() { endStreamOperation(span, len(results)) }()
+
+	c.chunked(ctx, len(geocodes), func(start, end int) {
+		copy(results[start:end], c.reverseGeocodingChunk(ctx, start, geocodes[start:end], filters))
+	})
+	return results
+}
+
+//BulkReverseGeocodingStream Reverse geocodes the geolocations received from the given channel in bulk reverse
+//geocoding requests of up to 100 geolocations, sending up to the configured concurrency of requests at once.
+//
+//The results are sent in completion order; their Index correlates them with the position of the geolocation in the
+//input. The returned channel is closed once the input channel is closed and all results are sent, or once the
+//context is done.
+func (c *Client) BulkReverseGeocodingStream(ctx context.Context, geocodes <-chan Geocode, filters []string) <-chan GeocodeResult {
+	ctx, span := c.startOperation(ctx, "BulkReverseGeocodingStream", 0)
+	out := make(chan GeocodeResult, maxBulkSize)
+	var sent int64
+	go func() {
+		defer func() { endStreamOperation(span, int(atomic.LoadInt64(&sent))) }()
+		defer close(out)
+
+		semaphore := make(chan struct{}, c.parallelism())
+		var wg sync.WaitGroup
+		defer wg.Wait()
+
+		offset := 0
+		batch := make([]Geocode, 0, maxBulkSize)
+		flush := func() bool {
+			if len(batch) == 0 {
+				return true
+			}
+			select {
+			case semaphore <- struct{}{}:
+			case <-ctx.Done():
+				return false
+			}
+
+			wg.Add(1)
+			go func(offset int, batch []Geocode) {
+				defer wg.Done()
+				defer func() { <-semaphore }()
+				for _, result := range c.reverseGeocodingChunk(ctx, offset, batch, filters) {
+					select {
+					case out <- result:
+						atomic.AddInt64(&sent, 1)
+					case <-ctx.Done():
+						return
+					}
+				}
+			}(offset, batch)
+
+			offset += len(batch)
+			batch = make([]Geocode, 0, maxBulkSize)
+			return true
+		}
+
+		for {
+			select {
+			case geocode, ok := <-geocodes:
+				if !ok {
+					flush()
+					return
+				}
+				batch = append(batch, geocode)
+				if len(batch) == maxBulkSize && !flush() {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+//reverseGeocodingChunk reverse geocodes up to maxBulkSize geolocations in a single request. Invalid geolocations
+//are reported individually and left out of the request.
+func (c *Client) reverseGeocodingChunk(ctx context.Context, offset int, geocodes []Geocode, filters []string) []GeocodeResult {
+	results := make([]GeocodeResult, len(geocodes))
+	var valid Geocodes
+	var validIndex []int
+	for i, geocode := range geocodes {
+		results[i] = GeocodeResult{Index: offset + i, Query: geocode}
+		if err := geocode.validate(); err != nil {
+			results[i].Error = err
+			continue
+		}
+		valid.Geolocations = append(valid.Geolocations, geocode)
+		validIndex = append(validIndex, i)
+	}
+	if len(validIndex) == 0 {
+		return results
+	}
+
+	data, err := c.BulkReverseGeocodingContext(ctx, valid, filters)
+	for j, i := range validIndex {
+		switch {
+		case err != nil:
+			results[i].Error = err
+		case j >= len(data):
+			results[i].Error = internal.ResponseDecodeError(errMissingResult)
+		default:
+			results[i].Result = data[j]
+		}
+	}
+	return results
+}
+
+//chunked calls fn for each chunk of up to maxBulkSize items out of total, running up to the configured
+//concurrency of chunks at once
+func (c *Client) chunked(ctx context.Context, total int, fn func(start, end int)) {
+	semaphore := make(chan struct{}, c.parallelism())
+	var wg sync.WaitGroup
+	for start := 0; start < total; start += maxBulkSize {
+		end := start + maxBulkSize
+		if end > total {
+			end = total
+		}
+
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+			// fails fast with the context error without waiting for a free slot
+			fn(start, end)
+			continue
+		}
+
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			fn(start, end)
+		}(start, end)
+	}
+	wg.Wait()
+}
+
+//parallelism returns the configured number of concurrent bulk requests
+func (c *Client) parallelism() int {
+	if c.concurrency <= 0 {
+		return defaultConcurrency
+	}
+	return c.concurrency
+}
+
+//lookupResult returns the outcome of looking up the postcode at the given index of a bulk lookup. Null results are
+//reported with the error of their online lookup if it left them unanswered, otherwise as invalid or not found.
+func lookupResult(query string, data []model.Postcodes, index int, err *model.ResponseError, skipped *unanswered) LookupResult {
+	result := LookupResult{Query: query}
+	switch {
+	case err != nil:
+		result.Error = err
+	case index >= len(data):
+		result.Error = internal.ResponseDecodeError(errMissingResult)
+	case data[index].Postcode.Postcode == "" && !valid(query):
+		result.Error = invalidPostcode("Invalid postcode")
+	case data[index].Postcode.Postcode == "" && skipped.error(normalise(query)) != nil:
+		result.Error = skipped.error(normalise(query))
+	case data[index].Postcode.Postcode == "":
+		result.Error = &model.ResponseError{Status: http.StatusNotFound, Message: "Postcode not found"}
+	default:
+		postcode := data[index].Postcode
+		result.Postcode = &postcode
+	}
+	return result
+}
@@ -0,0 +1,213 @@
+package postcode_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/razorcorp/postcode-sdk-go/model"
+	"github.com/razorcorp/postcode-sdk-go/postcode"
+	"github.com/razorcorp/postcode-sdk-go/postcode/postcodetest"
+)
+
+//unitLetters letters allowed in the unit of an inward code
+const unitLetters = "ABDEFGHJLNPQRSTUWXYZ"
+
+//sectorPostcodes returns n postcodes of the given sector, e.g. RG4 7, of up to 400
+func sectorPostcodes(sector string, n int) []model.Postcode {
+	postcodes := make([]model.Postcode, n)
+	for i := range postcodes {
+		unit := string(unitLetters[i/len(unitLetters)]) + string(unitLetters[i%len(unitLetters)])
+		postcodes[i] = model.Postcode{
+			Postcode:  sector + unit,
+			Quality:   1,
+			Country:   "England",
+			Latitude:  51.47 + float64(i)*0.0001,
+			Longitude: -0.95,
+		}
+	}
+	return postcodes
+}
+
+//newSectorFake returns a fake server holding n postcodes of the given sector
+func newSectorFake(t *testing.T, sector string, n int) (*postcodetest.Server, []model.Postcode) {
+	t.Helper()
+	postcodes := sectorPostcodes(sector, n)
+	fake := postcodetest.NewServer(postcodetest.Dataset{Postcodes: postcodes})
+	t.Cleanup(fake.Close)
+	return fake, postcodes
+}
+
+func TestLookupAllBatches(t *testing.T) {
+	fake, postcodes := newSectorFake(t, "RG4 7", 250)
+	var queries [][]string
+	client := fake.Client(postcode.WithMiddleware(recordBulkQueries(t, &queries)))
+
+	// in reverse order and compact lower case, with an unknown and a malformed postcode in the middle
+	var inputs []string
+	for i := len(postcodes) - 1; i >= 0; i-- {
+		inputs = append(inputs, strings.ToLower(strings.ReplaceAll(postcodes[i].Postcode, " ", "")))
+		if i == 125 {
+			inputs = append(inputs, "RG4 7ZZ", "not a postcode")
+		}
+	}
+
+	results := client.LookupAll(inputs, nil)
+	if len(results) != len(inputs) {
+		t.Fatalf("LookupAll() returned %d results, want %d", len(results), len(inputs))
+	}
+	for i, result := range results {
+		if result.Query != inputs[i] {
+			t.Fatalf("result %d query = %q, want %q", i, result.Query, inputs[i])
+		}
+		switch result.Query {
+		case "RG4 7ZZ":
+			if result.Postcode != nil || !errors.Is(result.Error, model.ErrNotFound) {
+				t.Errorf("result %d = %+v, want not found", i, result)
+			}
+		case "not a postcode":
+			if result.Postcode != nil || !errors.Is(result.Error, model.ErrInvalidPostcode) {
+				t.Errorf("result %d = %+v, want invalid", i, result)
+			}
+		default:
+			if result.Error != nil || strings.ReplaceAll(result.Postcode.Postcode, " ", "") != strings.ToUpper(result.Query) {
+				t.Errorf("result %d = %+v, want the postcode of %q", i, result, result.Query)
+			}
+		}
+	}
+
+	if len(queries) != 3 {
+		t.Fatalf("bulk lookups = %d, want 3", len(queries))
+	}
+	sent := 0
+	for _, query := range queries {
+		if len(query) > 100 {
+			t.Errorf("bulk lookup of %d postcodes, want up to 100", len(query))
+		}
+		sent += len(query)
+	}
+	if sent != len(inputs)-1 {
+		t.Errorf("postcodes sent = %d, want every well-formed postcode once", sent)
+	}
+}
+
+func TestLookupAllFailedBatch(t *testing.T) {
+	fake, postcodes := newSectorFake(t, "RG4 7", 250)
+	client := fake.Client(postcode.WithConcurrency(1))
+	fake.Inject(postcodetest.Fault{
+		Kind:     postcodetest.FaultStatus,
+		Method:   http.MethodPost,
+		Endpoint: "postcodes",
+		After:    1,
+		Times:    1,
+		Status:   http.StatusInternalServerError,
+	})
+
+	inputs := make([]string, len(postcodes))
+	for i := range postcodes {
+		inputs[i] = postcodes[i].Postcode
+	}
+	for i, result := range client.LookupAll(inputs, nil) {
+		failed := i >= 100 && i < 200
+		if failed && (result.Postcode != nil || !errors.Is(result.Error, model.ErrServer)) {
+			t.Errorf("result %d of the failed batch = %+v, want a server error", i, result)
+		}
+		if !failed && (result.Error != nil || result.Postcode.Postcode != inputs[i]) {
+			t.Errorf("result %d = %+v, want %s", i, result, inputs[i])
+		}
+	}
+}
+
+func TestBulkReverseGeocodingStream(t *testing.T) {
+	fake := newFake(t)
+	client := fake.Client()
+
+	reading := postcode.Geocode{Latitude: 51.456813, Longitude: -0.971396}
+	edinburgh := postcode.Geocode{Latitude: 55.950880, Longitude: -3.189820}
+	geocodes := make([]postcode.Geocode, 250)
+	for i := range geocodes {
+		geocodes[i] = reading
+		if i%2 == 1 {
+			geocodes[i] = edinburgh
+		}
+		geocodes[i].Limit = int64(i%5 + 1)
+	}
+	geocodes[7] = postcode.Geocode{}
+
+	in := make(chan postcode.Geocode)
+	go func() {
+		defer close(in)
+		for _, geocode := range geocodes {
+			in <- geocode
+		}
+	}()
+
+	seen := make(map[int]bool)
+	for result := range client.BulkReverseGeocodingStream(context.Background(), in, nil) {
+		if seen[result.Index] {
+			t.Errorf("result %d sent twice", result.Index)
+		}
+		seen[result.Index] = true
+		if result.Query != geocodes[result.Index] {
+			t.Errorf("result %d query = %+v, want %+v", result.Index, result.Query, geocodes[result.Index])
+		}
+
+		want := "RG1 1AF"
+		switch {
+		case result.Index == 7:
+			if !errors.Is(result.Error, model.ErrValidation) {
+				t.Errorf("result 7 error = %v, want a validation error", result.Error)
+			}
+			continue
+		case result.Index%2 == 1:
+			want = "EH1 1YZ"
+		}
+		if result.Error != nil || len(result.Result.Postcode) == 0 || result.Result.Postcode[0].Postcode != want {
+			t.Errorf("result %d = %+v, want %s first", result.Index, result, want)
+		}
+	}
+	if len(seen) != len(geocodes) {
+		t.Errorf("results = %d, want %d", len(seen), len(geocodes))
+	}
+	if calls := fake.Calls(http.MethodPost, "postcodes"); calls != 3 {
+		t.Errorf("bulk requests = %d, want 3", calls)
+	}
+}
+
+func TestBulkReverseGeocodingStreamCancelled(t *testing.T) {
+	fake := newFake(t)
+	fake.Inject(postcodetest.Fault{Kind: postcodetest.FaultLatency, Latency: 500 * time.Millisecond})
+	client := fake.Client()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan postcode.Geocode)
+	out := client.BulkReverseGeocodingStream(ctx, in, nil)
+
+	// a full batch in flight and a partial one queued, with the input left open
+	for i := 0; i < 150; i++ {
+		in <- postcode.Geocode{Latitude: 51.456813, Longitude: -0.971396}
+	}
+	for fake.Calls(http.MethodPost, "postcodes") == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+
+	// well before the response of the batch in flight
+	timeout := time.After(250 * time.Millisecond)
+	for {
+		select {
+		case result, ok := <-out:
+			if !ok {
+				return
+			}
+			if !errors.Is(result.Error, context.Canceled) {
+				t.Errorf("result %d after cancellation = %+v, want cancelled", result.Index, result)
+			}
+		case <-timeout:
+			t.Fatal("results channel not closed after the context was cancelled")
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package postcode
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"github.com/razorcorp/postcode-sdk-go/model"
+	"github.com/razorcorp/postcode-sdk-go/postcode/internal"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type (
+	//Cache stores the decoded results of the lookup endpoints. Implementations must be safe for concurrent use and
+	//must not return expired entries.
+	Cache interface {
+		Get(key string) (CacheEntry, bool)
+		Set(key string, entry CacheEntry)
+		Delete(key string)
+	}
+
+	//CacheEntry is a cached lookup result
+	CacheEntry struct {
+		//Value decoded result, e.g. *model.Postcode, *model.OutcodeData or *model.Place
+		Value interface{}
+
+		//Status HTTP status of a cached error response. Non-zero for negative entries
+		Status int
+
+		//Message error message of a cached error response
+		Message string
+
+		//Expires time after which the entry must no longer be served
+		Expires time.Time
+	}
+
+	//CacheTTL time to live of the cached results per endpoint. A zero TTL disables caching of the endpoint.
+	CacheTTL struct {
+		//Postcode Lookup and BulkLookup results
+		Postcode time.Duration
+
+		//Outcode OutcodeLookup results
+		Outcode time.Duration
+
+		//Place PlaceLookup results
+		Place time.Duration
+
+		//Scottish ScottishPostcodeLookup results
+		Scottish time.Duration
+
+		//Negative 404 responses, e.g. invalid or unknown postcodes
+		Negative time.Duration
+	}
+
+	//CacheStats reports the effectiveness of a cache
+	CacheStats struct {
+		Hits      int64
+		Misses    int64
+		Evictions int64
+		Entries   int
+	}
+
+	//MemoryCache is a bounded in-memory Cache evicting the least recently used entries
+	MemoryCache struct {
+		mu         sync.Mutex
+		maxEntries int
+		entries    map[string]*list.Element
+		order      *list.List
+		stats      CacheStats
+	}
+
+	memoryItem struct {
+		key   string
+		entry CacheEntry
+	}
+)
+
+//DefaultCacheTTL caches lookup results for a day and 404 responses for an hour
+var DefaultCacheTTL = CacheTTL{
+	Postcode: 24 * time.Hour,
+	Outcode:  24 * time.Hour,
+	Place:    24 * time.Hour,
+	Scottish: 24 * time.Hour,
+	Negative: time.Hour,
+}
+
+//WithCache serves the results of Lookup, BulkLookup, OutcodeLookup, PlaceLookup and ScottishPostcodeLookup from the
+//given cache, storing them for the given time to live
+func WithCache(cache Cache, ttl CacheTTL) Option {
+	return func(c *Client) {
+		c.cache = cache
+		c.cacheTTL = ttl
+	}
+}
+
+//NewMemoryCache returns a MemoryCache holding up to maxEntries entries
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+//Get returns the entry stored for the key unless it has expired
+func (m *MemoryCache) Get(key string) (CacheEntry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	element, ok := m.entries[key]
+	if ok && element.Value.(*memoryItem).entry.expired() {
+		m.remove(element)
+		ok = false
+	}
+	if !ok {
+		m.stats.Misses++
+		return CacheEntry{}, false
+	}
+
+	m.stats.Hits++
+	m.order.MoveToFront(element)
+	return element.Value.(*memoryItem).entry, true
+}
+
+//Set stores the entry for the key, evicting the least recently used entries when the cache is full
+func (m *MemoryCache) Set(key string, entry CacheEntry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if element, ok := m.entries[key]; ok {
+		element.Value.(*memoryItem).entry = entry
+		m.order.MoveToFront(element)
+		return
+	}
+
+	m.entries[key] = m.order.PushFront(&memoryItem{key: key, entry: entry})
+	for m.maxEntries > 0 && m.order.Len() > m.maxEntries {
+		m.remove(m.order.Back())
+		m.stats.Evictions++
+	}
+}
+
+//Delete removes the entry stored for the key
+func (m *MemoryCache) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if element, ok := m.entries[key]; ok {
+		m.remove(element)
+	}
+}
+
+//Stats returns the hit, miss and eviction counts of the cache
+func (m *MemoryCache) Stats() CacheStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := m.stats
+	stats.Entries = m.order.Len()
+	return stats
+}
+
+func (m *MemoryCache) remove(element *list.Element) {
+	m.order.Remove(element)
+	delete(m.entries, element.Value.(*memoryItem).key)
+}
+
+func (e CacheEntry) expired() bool {
+	return !e.Expires.IsZero() && time.Now().After(e.Expires)
+}
+
+//cached serves the result from the cache when available. Otherwise the result of fetch is stored in the cache,
+//including 404 responses when negative caching is enabled. Results served by the local dataset of a failover client
+//are not stored, so that postcodes.io answers once it is reachable again.
+func (c *Client) cached(ctx context.Context, key string, ttl time.Duration, fetch func() (interface{}, *model.ResponseError)) (interface{}, *model.ResponseError) {
+	if c.cache == nil || ttl <= 0 {
+		return fetch()
+	}
+
+	entry, ok := c.cache.Get(key)
+	if c.metrics != nil {
+		c.metrics.ObserveCache(internal.Endpoint(key), ok)
+	}
+	if ok {
+		c.logCache(ctx, key, 1, 1)
+		if entry.Status != 0 {
+			return nil, &model.ResponseError{Status: entry.Status, Message: entry.Message}
+		}
+		return entry.Value, nil
+	}
+
+	c.logCache(ctx, key, 0, 1)
+	value, err := fetch()
+	switch {
+	case err == nil:
+		if fromFallback(value) {
+			break
+		}
+		c.cache.Set(key, CacheEntry{Value: value, Expires: time.Now().Add(ttl)})
+	case err.Status == http.StatusNotFound && c.cacheTTL.Negative > 0:
+		c.cache.Set(key, CacheEntry{
+			Status:  err.Status,
+			Message: err.Message,
+			Expires: time.Now().Add(c.cacheTTL.Negative),
+		})
+	}
+	return value, err
+}
+
+//bulkLookupCached serves the cached postcodes locally and looks up only the cache misses
+func (c *Client) bulkLookupCached(ctx context.Context, postcodes Postcodes) ([]model.Postcodes, *model.ResponseError) {
+	data := make([]model.Postcodes, len(postcodes.Postcodes))
+	var misses Postcodes
+	var missIndex []int
+	for i, postcode := range postcodes.Postcodes {
+		data[i].Query = postcode
+		key := cacheKey("postcodes", postcode)
+		entry, ok := c.cache.Get(key)
+		if c.metrics != nil {
+			c.metrics.ObserveCache(internal.Endpoint(key), ok)
+		}
+		if !ok {
+			misses.Postcodes = append(misses.Postcodes, postcode)
+			missIndex = append(missIndex, i)
+			continue
+		}
+		if entry.Status == 0 {
+			data[i].Postcode = *entry.Value.(*model.Postcode)
+		}
+	}
+
+	c.logCache(ctx, "postcodes", len(postcodes.Postcodes)-len(misses.Postcodes), len(postcodes.Postcodes))
+	if len(misses.Postcodes) == 0 {
+		return data, nil
+	}
+
+	ctx, skipped := withUnanswered(ctx)
+	results, err := c.bulkLookup(ctx, misses, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for j, result := range results {
+		if j >= len(missIndex) {
+			break
+		}
+		data[missIndex[j]] = result
+
+		key := cacheKey("postcodes", misses.Postcodes[j])
+		if result.Postcode.Fallback != nil || skipped.error(misses.Postcodes[j]) != nil {
+			continue
+		}
+		if result.Postcode.Postcode == "" {
+			if c.cacheTTL.Negative > 0 {
+				c.cache.Set(key, CacheEntry{
+					Status:  http.StatusNotFound,
+					Message: "Postcode not found",
+					Expires: time.Now().Add(c.cacheTTL.Negative),
+				})
+			}
+			continue
+		}
+		postcode := result.Postcode
+		c.cache.Set(key, CacheEntry{Value: &postcode, Expires: time.Now().Add(c.cacheTTL.Postcode)})
+	}
+
+	return data, nil
+}
+
+//cacheKey returns the cache key of the endpoint resource, normalising the postcode so that different spellings
+//of the same postcode share an entry
+func cacheKey(endpoint, postcode string) string {
+	return fmt.Sprintf("%s/%s", endpoint, normalise(postcode))
+}
@@ -0,0 +1,188 @@
+package postcode_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/razorcorp/postcode-sdk-go/model"
+	"github.com/razorcorp/postcode-sdk-go/postcode"
+)
+
+//recordBulkQueries returns a middleware recording the postcodes sent by each bulk lookup
+func recordBulkQueries(t *testing.T, queries *[][]string) postcode.Middleware {
+	var mu sync.Mutex
+	return func(next postcode.Doer) postcode.Doer {
+		return postcode.DoerFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Method == http.MethodPost {
+				body, err := io.ReadAll(req.Body)
+				if err != nil {
+					t.Errorf("reading bulk request: %v", err)
+				}
+				req.Body = io.NopCloser(bytes.NewReader(body))
+				var request postcode.Postcodes
+				if err := json.Unmarshal(body, &request); err != nil {
+					t.Errorf("decoding bulk request: %v", err)
+				}
+				mu.Lock()
+				*queries = append(*queries, request.Postcodes)
+				mu.Unlock()
+			}
+			return next.Do(req)
+		})
+	}
+}
+
+func TestMemoryCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := postcode.NewMemoryCache(2)
+	cache.Set("a", postcode.CacheEntry{Value: "a"})
+	cache.Set("b", postcode.CacheEntry{Value: "b"})
+	if _, ok := cache.Get("a"); !ok {
+		t.Fatal("Get(a) missed")
+	}
+	cache.Set("c", postcode.CacheEntry{Value: "c"})
+
+	if _, ok := cache.Get("b"); ok {
+		t.Error("Get(b) hit, want the least recently used entry evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if entry, ok := cache.Get(key); !ok || entry.Value != key {
+			t.Errorf("Get(%s) = %+v, %v, want the recently used entry kept", key, entry, ok)
+		}
+	}
+
+	cache.Set("a", postcode.CacheEntry{Value: "updated"})
+	cache.Set("d", postcode.CacheEntry{Value: "d"})
+	if entry, ok := cache.Get("a"); !ok || entry.Value != "updated" {
+		t.Errorf("Get(a) = %+v, %v, want the updated entry kept", entry, ok)
+	}
+	if _, ok := cache.Get("c"); ok {
+		t.Error("Get(c) hit, want it evicted after a was updated")
+	}
+
+	want := postcode.CacheStats{Hits: 4, Misses: 2, Evictions: 2, Entries: 2}
+	if stats := cache.Stats(); stats != want {
+		t.Errorf("Stats() = %+v, want %+v", stats, want)
+	}
+}
+
+func TestMemoryCacheExpiry(t *testing.T) {
+	cache := postcode.NewMemoryCache(10)
+	cache.Set("expired", postcode.CacheEntry{Value: 1, Expires: time.Now().Add(-time.Second)})
+	cache.Set("fresh", postcode.CacheEntry{Value: 2, Expires: time.Now().Add(time.Hour)})
+	cache.Set("forever", postcode.CacheEntry{Value: 3})
+
+	if _, ok := cache.Get("expired"); ok {
+		t.Error("Get(expired) hit, want expired entries never served")
+	}
+	for _, key := range []string{"fresh", "forever"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("Get(%s) missed", key)
+		}
+	}
+	if stats := cache.Stats(); stats.Entries != 2 || stats.Misses != 1 {
+		t.Errorf("Stats() = %+v, want the expired entry removed and counted as a miss", stats)
+	}
+}
+
+func TestCacheNormalisesKeys(t *testing.T) {
+	fake := newFake(t)
+	client := fake.Client(postcode.WithCache(postcode.NewMemoryCache(100), postcode.DefaultCacheTTL))
+
+	for _, query := range []string{"rg122pe", "RG12 2PE", " rg12 2pe "} {
+		data, err := client.Lookup(query)
+		if err != nil || data.Postcode != "RG12 2PE" {
+			t.Errorf("Lookup(%q) = %+v, %v, want RG12 2PE", query, data, err)
+		}
+	}
+	if calls := fake.Calls(http.MethodGet, "postcodes/:postcode"); calls != 1 {
+		t.Errorf("GET calls = %d, want every spelling served from a single entry", calls)
+	}
+}
+
+func TestCacheExpiry(t *testing.T) {
+	fake := newFake(t)
+	client := fake.Client(postcode.WithCache(postcode.NewMemoryCache(100), postcode.CacheTTL{Postcode: 20 * time.Millisecond}))
+
+	client.Lookup("RG1 1AF")
+	client.Lookup("RG1 1AF")
+	if calls := fake.Calls(http.MethodGet, "postcodes/:postcode"); calls != 1 {
+		t.Fatalf("GET calls = %d, want the second lookup served from the cache", calls)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if data, err := client.Lookup("RG1 1AF"); err != nil || data.Postcode != "RG1 1AF" {
+		t.Errorf("Lookup() after expiry = %+v, %v", data, err)
+	}
+	if calls := fake.Calls(http.MethodGet, "postcodes/:postcode"); calls != 2 {
+		t.Errorf("GET calls = %d, want the expired entry fetched again", calls)
+	}
+}
+
+func TestCacheNotFound(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		negative time.Duration
+		want     int
+	}{
+		{"negative caching", time.Hour, 1},
+		{"negative caching disabled", 0, 2},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fake := newFake(t)
+			client := fake.Client(postcode.WithCache(postcode.NewMemoryCache(100), postcode.CacheTTL{Postcode: time.Hour, Negative: test.negative}))
+
+			for i := 0; i < 2; i++ {
+				if _, err := client.Lookup("RG1 9ZZ"); !errors.Is(err, model.ErrNotFound) {
+					t.Errorf("Lookup() %d error = %v, want not found", i, err)
+				}
+			}
+			if calls := fake.Calls(http.MethodGet, "postcodes/:postcode"); calls != test.want {
+				t.Errorf("GET calls = %d, want %d", calls, test.want)
+			}
+		})
+	}
+}
+
+func TestCacheBulkLookupPartialHit(t *testing.T) {
+	fake := newFake(t)
+	var queries [][]string
+	client := fake.Client(
+		postcode.WithCache(postcode.NewMemoryCache(100), postcode.DefaultCacheTTL),
+		postcode.WithMiddleware(recordBulkQueries(t, &queries)),
+	)
+	if _, err := client.Lookup("RG1 1AF"); err != nil {
+		t.Fatal(err)
+	}
+
+	postcodes := postcode.Postcodes{Postcodes: []string{"rg11af", "RG1 1AZ", "RG1 9ZZ", "not a postcode"}}
+	for i := 0; i < 2; i++ {
+		bulk, err := client.BulkLookup(postcodes, nil)
+		if err != nil || len(bulk) != 4 {
+			t.Fatalf("BulkLookup() %d = %v, %v", i, bulk, err)
+		}
+		for j, want := range []string{"RG1 1AF", "RG1 1AZ", "", ""} {
+			if bulk[j].Query != postcodes.Postcodes[j] || bulk[j].Postcode.Postcode != want {
+				t.Errorf("BulkLookup() %d result %d = %q for %q, want %q", i, j, bulk[j].Postcode.Postcode, bulk[j].Query, want)
+			}
+		}
+	}
+	if want := [][]string{{"RG1 1AZ", "RG1 9ZZ"}}; !reflect.DeepEqual(queries, want) {
+		t.Errorf("bulk lookups sent = %v, want only the cache misses once", queries)
+	}
+
+	if data, err := client.Lookup("RG1 1AZ"); err != nil || data.Postcode != "RG1 1AZ" {
+		t.Errorf("Lookup() of a bulk result = %+v, %v", data, err)
+	}
+	if _, err := client.Lookup("RG1 9ZZ"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("Lookup() of a null bulk result error = %v, want not found", err)
+	}
+	if calls := fake.Calls(http.MethodGet, "postcodes/:postcode"); calls != 1 {
+		t.Errorf("GET calls = %d, want the bulk results cached for Lookup", calls)
+	}
+}
@@ -0,0 +1,785 @@
+package postcode
+
+import (
+	"context"
+	"fmt"
+	"github.com/razorcorp/postcode-sdk-go/model"
+	"github.com/razorcorp/postcode-sdk-go/postcode/internal"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	//Client is a configurable postcodes.io API client. A Client is safe for concurrent use by multiple goroutines
+	//and should be reused so that the underlying HTTP connections are shared.
+	Client struct {
+		url         string
+		httpClient  *http.Client
+		headers     []internal.Header
+		timeout     time.Duration
+		retry       RetryPolicy
+		limiter     *RateLimiter
+		cache       Cache
+		cacheTTL    CacheTTL
+		concurrency int
+		batcher     *batcher
+		logger      *slog.Logger
+		logOptions  LogOptions
+		metrics     Metrics
+		tracer      Tracer
+		backend     Backend
+		doer        Doer
+		middleware  []Middleware
+		transport   Doer
+	}
+
+	//Option configures a Client
+	Option func(*Client)
+)
+
+//DefaultClient is the Client used by the package level functions
+var DefaultClient = NewClient()
+
+//NewClient returns a new Client configured with the given options.
+//
+//Without any options the Client targets https://api.postcodes.io using a shared HTTP client.
+func NewClient(options ...Option) *Client {
+	c := &Client{
+		url: internal.API,
+	}
+	for _, option := range options {
+		option(c)
+	}
+
+	if c.timeout > 0 {
+		httpClient := new(http.Client)
+		if c.httpClient != nil {
+			*httpClient = *c.httpClient
+		}
+		httpClient.Timeout = c.timeout
+		c.httpClient = httpClient
+	}
+	if c.batcher != nil {
+		c.batcher.client = c
+	}
+	c.transport = c.chain()
+
+	return c
+}
+
+//WithBaseURL sets the base URL of the API, e.g. the address of a self-hosted postcodes.io mirror
+func WithBaseURL(url string) Option {
+	return func(c *Client) {
+		c.url = strings.TrimRight(url, "/")
+	}
+}
+
+//WithHTTPClient sets the HTTP client used to execute the requests
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+//WithHeader adds a header sent with every request. Overrides the default header with the same key.
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		c.headers = append(c.headers, internal.Header{Key: key, Value: value})
+	}
+}
+
+//WithTimeout sets the time limit for each request made by the Client
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+//Lookup Returns a single postcode entity for a given postcode (case, space insensitive).
+func (c *Client) Lookup(postcode string) (*model.Postcode, *model.ResponseError) {
+	return c.LookupContext(context.Background(), postcode)
+}
+
+//LookupContext is like Lookup but carries the given context through to the request
+func (c *Client) LookupContext(ctx context.Context, postcode string) (result *model.Postcode, err *model.ResponseError) {
+	ctx, span := c.startOperation(ctx, "Lookup", 0)
+	defer func() { endOperation(span, result, err) }()
+
+	parts, parseErr := parse(postcode)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	postcode = parts.String()
+
+	value, err := c.cached(ctx, cacheKey("postcodes", postcode), c.cacheTTL.Postcode, func() (interface{}, *model.ResponseError) {
+		if c.backend != nil {
+			return c.backend.Lookup(ctx, postcode)
+		}
+		if c.batcher != nil {
+			return c.batcher.lookup(ctx, postcode)
+		}
+		return c.apiLookup(ctx, postcode)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	data := *value.(*model.Postcode)
+	return &data, nil
+}
+
+func (c *Client) apiLookup(ctx context.Context, postcode string) (*model.Postcode, *model.ResponseError) {
+	data := new(model.Postcode)
+	if err := c.send(ctx, http.MethodGet, fmt.Sprintf("postcodes/%s", url.PathEscape(postcode)), nil, nil, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+//BulkLookup Returns a list of matching postcodes and respective available data. Accepts up to 100 postcodes.
+func (c *Client) BulkLookup(postcodes Postcodes, filters []string) ([]model.Postcodes, *model.ResponseError) {
+	return c.BulkLookupContext(context.Background(), postcodes, filters)
+}
+
+//BulkLookupContext is like BulkLookup but carries the given context through to the request
+func (c *Client) BulkLookupContext(ctx context.Context, postcodes Postcodes, filters []string) (result []model.Postcodes, err *model.ResponseError) {
+	ctx, span := c.startOperation(ctx, "BulkLookup", len(postcodes.Postcodes))
+	defer func() { endOperation(span, result, err) }()
+
+	if err := postcodes.validate(); err != nil {
+		return nil, err
+	}
+
+	// malformed postcodes are answered locally with a null result, as the API would do
+	data := make([]model.Postcodes, len(postcodes.Postcodes))
+	var valid Postcodes
+	var validIndex []int
+	for i, postcode := range postcodes.Postcodes {
+		data[i].Query = postcode
+		if parts, err := parse(postcode); err == nil {
+			valid.Postcodes = append(valid.Postcodes, parts.String())
+			validIndex = append(validIndex, i)
+		}
+	}
+	if len(validIndex) == 0 {
+		return data, nil
+	}
+
+	var results []model.Postcodes
+	if c.cache != nil && c.cacheTTL.Postcode > 0 && len(filters) == 0 {
+		results, err = c.bulkLookupCached(ctx, valid)
+	} else {
+		results, err = c.bulkLookup(ctx, valid, filters)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for j, i := range validIndex {
+		if j < len(results) {
+			data[i].Postcode = results[j].Postcode
+		}
+	}
+	return data, nil
+}
+
+func (c *Client) bulkLookup(ctx context.Context, postcodes Postcodes, filters []string) ([]model.Postcodes, *model.ResponseError) {
+	if c.backend != nil {
+		results, err := c.backend.BulkLookup(ctx, postcodes.Postcodes)
+		if err != nil {
+			return nil, err
+		}
+		return filterPostcodes(results, filters)
+	}
+	return c.apiBulkLookup(ctx, postcodes, filters)
+}
+
+func (c *Client) apiBulkLookup(ctx context.Context, postcodes Postcodes, filters []string) ([]model.Postcodes, *model.ResponseError) {
+	payload, payloadErr := postcodes.json()
+	if payloadErr != nil {
+		return nil, internal.PayloadEncodeError(payloadErr)
+	}
+
+	var data []model.Postcodes
+	if err := c.send(withBatchSize(ctx, len(postcodes.Postcodes)), http.MethodPost, "postcodes", filterQuery(filters), payload, &data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+//ReverseGeocoding Returns the nearest postcodes for a given longitude and latitude.
+func (c *Client) ReverseGeocoding(geocode Geocode) ([]model.Postcode, *model.ResponseError) {
+	return c.ReverseGeocodingContext(context.Background(), geocode)
+}
+
+//ReverseGeocodingContext is like ReverseGeocoding but carries the given context through to the request
+func (c *Client) ReverseGeocodingContext(ctx context.Context, geocode Geocode) (result []model.Postcode, err *model.ResponseError) {
+	ctx, span := c.startOperation(ctx, "ReverseGeocoding", 0)
+	defer func() { endOperation(span, result, err) }()
+
+	if err := geocode.validate(); err != nil {
+		return nil, err
+	}
+
+	query := geocode.query()
+	if geocode.WideSearch {
+		query = append(query, internal.Query{
+			Key:   "widesearch",
+			Value: strconv.FormatBool(geocode.WideSearch),
+		})
+	}
+
+	var data []model.Postcode
+	if err := c.send(ctx, http.MethodGet, "postcodes", query, nil, &data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+//BulkReverseGeocoding Bulk translates geolocations into Postcodes. Accepts up to 100 geolocations.
+func (c *Client) BulkReverseGeocoding(geocodes Geocodes, filters []string) ([]model.Geocodes, *model.ResponseError) {
+	return c.BulkReverseGeocodingContext(context.Background(), geocodes, filters)
+}
+
+//BulkReverseGeocodingContext is like BulkReverseGeocoding but carries the given context through to the request
+func (c *Client) BulkReverseGeocodingContext(ctx context.Context, geocodes Geocodes, filters []string) (result []model.Geocodes, err *model.ResponseError) {
+	ctx, span := c.startOperation(ctx, "BulkReverseGeocoding", len(geocodes.Geolocations))
+	defer func() { endOperation(span, result, err) }()
+
+	if err := geocodes.validate(); err != nil {
+		return nil, err
+	}
+
+	payload, payloadErr := geocodes.json()
+	if payloadErr != nil {
+		return nil, internal.PayloadEncodeError(payloadErr)
+	}
+
+	var data []model.Geocodes
+	if err := c.send(withBatchSize(ctx, len(geocodes.Geolocations)), http.MethodPost, "postcodes", filterQuery(filters), payload, &data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+//Query Returns a list of postcodes prefix matching the given postcode, with all associated postcode data.
+func (c *Client) Query(postcode string, limit *int64) ([]model.Postcode, *model.ResponseError) {
+	return c.QueryContext(context.Background(), postcode, limit)
+}
+
+//QueryContext is like Query but carries the given context through to the request
+func (c *Client) QueryContext(ctx context.Context, postcode string, limit *int64) (result []model.Postcode, err *model.ResponseError) {
+	ctx, span := c.startOperation(ctx, "Query", 0)
+	defer func() { endOperation(span, result, err) }()
+
+	if err := validateLimit(limit); err != nil {
+		return nil, err
+	}
+
+	if c.backend != nil {
+		return c.backend.Query(ctx, strings.TrimSpace(postcode), limitOrDefault(limit))
+	}
+	return c.apiQuery(ctx, strings.TrimSpace(postcode), limit)
+}
+
+func (c *Client) apiQuery(ctx context.Context, postcode string, limit *int64) ([]model.Postcode, *model.ResponseError) {
+	query := []internal.Query{{Key: "q", Value: postcode}}
+	if limit != nil {
+		query = append(query, internal.Query{
+			Key:   "limit",
+			Value: strconv.FormatInt(*limit, 10),
+		})
+	}
+
+	var data []model.Postcode
+	if err := c.send(ctx, http.MethodGet, "postcodes", query, nil, &data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+//Validation Returns true or false (meaning valid or invalid respectively) for the given postcode.
+func (c *Client) Validation(postcode string) (bool, *model.ResponseError) {
+	return c.ValidationContext(context.Background(), postcode)
+}
+
+//ValidationContext is like Validation but carries the given context through to the request
+func (c *Client) ValidationContext(ctx context.Context, postcode string) (result bool, err *model.ResponseError) {
+	ctx, span := c.startOperation(ctx, "Validation", 0)
+	defer func() { endOperation(span, result, err) }()
+
+	parts, parseErr := parse(postcode)
+	if parseErr != nil {
+		return false, nil
+	}
+	postcode = parts.String()
+
+	if c.backend != nil {
+		return c.backend.Validation(ctx, postcode)
+	}
+	return c.apiValidation(ctx, postcode)
+}
+
+func (c *Client) apiValidation(ctx context.Context, postcode string) (bool, *model.ResponseError) {
+	var data bool
+	if err := c.send(ctx, http.MethodGet, fmt.Sprintf("postcodes/%s/validate", url.PathEscape(postcode)), nil, nil, &data); err != nil {
+		return false, err
+	}
+
+	return data, nil
+}
+
+//NearestPostcode Returns nearest postcodes for a given postcode.
+func (c *Client) NearestPostcode(postcode string, limit, radius *int64) ([]model.Postcode, *model.ResponseError) {
+	return c.NearestPostcodeContext(context.Background(), postcode, limit, radius)
+}
+
+//NearestPostcodeContext is like NearestPostcode but carries the given context through to the request
+func (c *Client) NearestPostcodeContext(ctx context.Context, postcode string, limit, radius *int64) (result []model.Postcode, err *model.ResponseError) {
+	ctx, span := c.startOperation(ctx, "NearestPostcode", 0)
+	defer func() { endOperation(span, result, err) }()
+
+	parts, parseErr := parse(postcode)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	postcode = parts.String()
+
+	if err := validateLimit(limit); err != nil {
+		return nil, err
+	}
+
+	if radius != nil && *radius > 2000 {
+		return nil, internal.ValidationError("Maximum radius exceeded! Radius must be less than 2,000m")
+	}
+
+	var query []internal.Query
+	if limit != nil {
+		query = append(query, internal.Query{
+			Key:   "limit",
+			Value: strconv.FormatInt(*limit, 10),
+		})
+	}
+	if radius != nil {
+		query = append(query, internal.Query{
+			Key:   "radius",
+			Value: strconv.FormatInt(*radius, 10),
+		})
+	}
+
+	var data []model.Postcode
+	if err := c.send(ctx, http.MethodGet, fmt.Sprintf("postcodes/%s/nearest", url.PathEscape(postcode)), query, nil, &data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+//Autocomplete Returns a list of postcodes matching the given partial postcode.
+func (c *Client) Autocomplete(postcode string, limit *int64) ([]string, *model.ResponseError) {
+	return c.AutocompleteContext(context.Background(), postcode, limit)
+}
+
+//AutocompleteContext is like Autocomplete but carries the given context through to the request
+func (c *Client) AutocompleteContext(ctx context.Context, postcode string, limit *int64) (result []string, err *model.ResponseError) {
+	ctx, span := c.startOperation(ctx, "Autocomplete", 0)
+	defer func() { endOperation(span, result, err) }()
+
+	if err := validateLimit(limit); err != nil {
+		return nil, err
+	}
+
+	if c.backend != nil {
+		return c.backend.Autocomplete(ctx, strings.TrimSpace(postcode), limitOrDefault(limit))
+	}
+	return c.apiAutocomplete(ctx, strings.TrimSpace(postcode), limit)
+}
+
+func (c *Client) apiAutocomplete(ctx context.Context, postcode string, limit *int64) ([]string, *model.ResponseError) {
+	var query []internal.Query
+	if limit != nil {
+		query = append(query, internal.Query{
+			Key:   "limit",
+			Value: strconv.FormatInt(*limit, 10),
+		})
+	}
+
+	var data []string
+	if err := c.send(ctx, http.MethodGet, fmt.Sprintf("postcodes/%s/autocomplete", url.PathEscape(postcode)), query, nil, &data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+//RandomPostcode Returns a random postcode and all available data for that postcode, optionally within the given
+//outcode.
+func (c *Client) RandomPostcode(outCode *string) (*model.Postcode, *model.ResponseError) {
+	return c.RandomPostcodeContext(context.Background(), outCode)
+}
+
+//RandomPostcodeContext is like RandomPostcode but carries the given context through to the request
+func (c *Client) RandomPostcodeContext(ctx context.Context, outCode *string) (result *model.Postcode, err *model.ResponseError) {
+	ctx, span := c.startOperation(ctx, "RandomPostcode", 0)
+	defer func() { endOperation(span, result, err) }()
+
+	var query []internal.Query
+	if outCode != nil {
+		canonical, parseErr := parseOutcode(*outCode)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		query = append(query, internal.Query{
+			Key:   "outcode",
+			Value: canonical,
+		})
+	}
+
+	data := new(model.Postcode)
+	if err := c.send(ctx, http.MethodGet, "random/postcodes", query, nil, data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+//OutcodeLookup Returns geolocation data for the centroid of the outward code specified.
+func (c *Client) OutcodeLookup(outCode string) (*model.OutcodeData, *model.ResponseError) {
+	return c.OutcodeLookupContext(context.Background(), outCode)
+}
+
+//OutcodeLookupContext is like OutcodeLookup but carries the given context through to the request
+func (c *Client) OutcodeLookupContext(ctx context.Context, outCode string) (result *model.OutcodeData, err *model.ResponseError) {
+	ctx, span := c.startOperation(ctx, "OutcodeLookup", 0)
+	defer func() { endOperation(span, result, err) }()
+
+	outCode, parseErr := parseOutcode(outCode)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	value, err := c.cached(ctx, cacheKey("outcodes", outCode), c.cacheTTL.Outcode, func() (interface{}, *model.ResponseError) {
+		if c.backend != nil {
+			return c.backend.OutcodeLookup(ctx, outCode)
+		}
+		return c.apiOutcodeLookup(ctx, outCode)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	data := *value.(*model.OutcodeData)
+	return &data, nil
+}
+
+func (c *Client) apiOutcodeLookup(ctx context.Context, outCode string) (*model.OutcodeData, *model.ResponseError) {
+	data := new(model.OutcodeData)
+	if err := c.send(ctx, http.MethodGet, fmt.Sprintf("outcodes/%s", url.PathEscape(outCode)), nil, nil, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+//OutcodeReverseGeocoding Returns nearest outcodes for a given longitude and latitude.
+func (c *Client) OutcodeReverseGeocoding(geocode Geocode) ([]model.OutcodeData, *model.ResponseError) {
+	return c.OutcodeReverseGeocodingContext(context.Background(), geocode)
+}
+
+//OutcodeReverseGeocodingContext is like OutcodeReverseGeocoding but carries the given context through to the request
+func (c *Client) OutcodeReverseGeocodingContext(ctx context.Context, geocode Geocode) (result []model.OutcodeData, err *model.ResponseError) {
+	ctx, span := c.startOperation(ctx, "OutcodeReverseGeocoding", 0)
+	defer func() { endOperation(span, result, err) }()
+
+	if err := geocode.validate(); err != nil {
+		return nil, err
+	}
+
+	var data []model.OutcodeData
+	if err := c.send(ctx, http.MethodGet, "outcodes", geocode.query(), nil, &data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+//NearestOutcode Returns nearest outcodes for a given outcode.
+func (c *Client) NearestOutcode(outCode string, limit, radius *int64) ([]model.OutcodeData, *model.ResponseError) {
+	return c.NearestOutcodeContext(context.Background(), outCode, limit, radius)
+}
+
+//NearestOutcodeContext is like NearestOutcode but carries the given context through to the request
+func (c *Client) NearestOutcodeContext(ctx context.Context, outCode string, limit, radius *int64) (result []model.OutcodeData, err *model.ResponseError) {
+	ctx, span := c.startOperation(ctx, "NearestOutcode", 0)
+	defer func() { endOperation(span, result, err) }()
+
+	outCode, parseErr := parseOutcode(outCode)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	var query []internal.Query
+	if limit != nil && *limit > 0 {
+		query = append(query, internal.Query{
+			Key:   "limit",
+			Value: strconv.FormatInt(*limit, 10),
+		})
+	}
+	if radius != nil && *radius > 0 {
+		query = append(query, internal.Query{
+			Key:   "radius",
+			Value: strconv.FormatInt(*radius, 10),
+		})
+	}
+
+	var data []model.OutcodeData
+	if err := c.send(ctx, http.MethodGet, fmt.Sprintf("outcodes/%s/nearest", url.PathEscape(outCode)), query, nil, &data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+//ScottishPostcodeLookup Returns SPD data associated with the given Scottish postcode.
+func (c *Client) ScottishPostcodeLookup(postcode string) (*model.ScottishPostcodeData, *model.ResponseError) {
+	return c.ScottishPostcodeLookupContext(context.Background(), postcode)
+}
+
+//ScottishPostcodeLookupContext is like ScottishPostcodeLookup but carries the given context through to the request
+func (c *Client) ScottishPostcodeLookupContext(ctx context.Context, postcode string) (result *model.ScottishPostcodeData, err *model.ResponseError) {
+	ctx, span := c.startOperation(ctx, "ScottishPostcodeLookup", 0)
+	defer func() { endOperation(span, result, err) }()
+
+	parts, parseErr := parse(postcode)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	postcode = parts.String()
+
+	value, err := c.cached(ctx, cacheKey("scotland/postcodes", postcode), c.cacheTTL.Scottish, func() (interface{}, *model.ResponseError) {
+		data := new(model.ScottishPostcodeData)
+		if err := c.send(ctx, http.MethodGet, fmt.Sprintf("scotland/postcodes/%s", url.PathEscape(postcode)), nil, nil, data); err != nil {
+			return nil, err
+		}
+		return data, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	data := *value.(*model.ScottishPostcodeData)
+	return &data, nil
+}
+
+//TerminatedPostcodeLookup Returns the postcode, year and month of termination for a terminated postcode.
+func (c *Client) TerminatedPostcodeLookup(postcode string) (*model.TerminatedPostcode, *model.ResponseError) {
+	return c.TerminatedPostcodeLookupContext(context.Background(), postcode)
+}
+
+//TerminatedPostcodeLookupContext is like TerminatedPostcodeLookup but carries the given context through to the request
+func (c *Client) TerminatedPostcodeLookupContext(ctx context.Context, postcode string) (result *model.TerminatedPostcode, err *model.ResponseError) {
+	ctx, span := c.startOperation(ctx, "TerminatedPostcodeLookup", 0)
+	defer func() { endOperation(span, result, err) }()
+
+	parts, parseErr := parse(postcode)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	postcode = parts.String()
+
+	if c.backend != nil {
+		return c.backend.TerminatedPostcodeLookup(ctx, postcode)
+	}
+	return c.apiTerminatedPostcodeLookup(ctx, postcode)
+}
+
+func (c *Client) apiTerminatedPostcodeLookup(ctx context.Context, postcode string) (*model.TerminatedPostcode, *model.ResponseError) {
+	data := new(model.TerminatedPostcode)
+	if err := c.send(ctx, http.MethodGet, fmt.Sprintf("terminated_postcodes/%s", url.PathEscape(postcode)), nil, nil, data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+//PlaceLookup Returns all available data for a place by OSGB code (e.g. "osgb4000000074564391").
+func (c *Client) PlaceLookup(osgbCode string) (*model.Place, *model.ResponseError) {
+	return c.PlaceLookupContext(context.Background(), osgbCode)
+}
+
+//PlaceLookupContext is like PlaceLookup but carries the given context through to the request
+func (c *Client) PlaceLookupContext(ctx context.Context, osgbCode string) (result *model.Place, err *model.ResponseError) {
+	ctx, span := c.startOperation(ctx, "PlaceLookup", 0)
+	defer func() { endOperation(span, result, err) }()
+
+	value, err := c.cached(ctx, cacheKey("places", osgbCode), c.cacheTTL.Place, func() (interface{}, *model.ResponseError) {
+		data := new(model.Place)
+		if err := c.send(ctx, http.MethodGet, fmt.Sprintf("places/%s", url.PathEscape(osgbCode)), nil, nil, data); err != nil {
+			return nil, err
+		}
+		return data, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	data := *value.(*model.Place)
+	return &data, nil
+}
+
+//PlaceQuery Returns a list of places matching the given place name and associated data.
+func (c *Client) PlaceQuery(query string, limit *int64) ([]model.Place, *model.ResponseError) {
+	return c.PlaceQueryContext(context.Background(), query, limit)
+}
+
+//PlaceQueryContext is like PlaceQuery but carries the given context through to the request
+func (c *Client) PlaceQueryContext(ctx context.Context, query string, limit *int64) (result []model.Place, err *model.ResponseError) {
+	ctx, span := c.startOperation(ctx, "PlaceQuery", 0)
+	defer func() { endOperation(span, result, err) }()
+
+	if err := validateLimit(limit); err != nil {
+		return nil, err
+	}
+
+	params := []internal.Query{{Key: "q", Value: query}}
+	if limit != nil && *limit > 0 {
+		params = append(params, internal.Query{
+			Key:   "limit",
+			Value: strconv.FormatInt(*limit, 10),
+		})
+	}
+
+	var data []model.Place
+	if err := c.send(ctx, http.MethodGet, "places", params, nil, &data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+//RandomPlace Returns a random place and all associated data
+func (c *Client) RandomPlace() (*model.Place, *model.ResponseError) {
+	return c.RandomPlaceContext(context.Background())
+}
+
+//RandomPlaceContext is like RandomPlace but carries the given context through to the request
+func (c *Client) RandomPlaceContext(ctx context.Context) (result *model.Place, err *model.ResponseError) {
+	ctx, span := c.startOperation(ctx, "RandomPlace", 0)
+	defer func() { endOperation(span, result, err) }()
+
+	data := new(model.Place)
+	if err := c.send(ctx, http.MethodGet, "random/places", nil, nil, data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+//send executes the API request, retrying it according to the retry policy, and decodes the response result into
+//data
+func (c *Client) send(ctx context.Context, method, uri string, query []internal.Query, payload []byte, data interface{}) *model.ResponseError {
+	ctx, span := c.startSpan(ctx, "postcodes "+method+" "+internal.Endpoint(uri))
+	span.SetAttribute("http.method", method)
+	span.SetAttribute("endpoint", internal.Endpoint(uri))
+	if size := batchSize(ctx); size > 0 {
+		span.SetAttribute("batch_size", size)
+	}
+
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		err := c.attempt(ctx, attempt, method, uri, query, payload, data)
+		if err == nil || attempt >= c.retry.MaxAttempts || !c.retry.allows(ctx, method) || !c.retry.retryable(err) {
+			c.logRequest(ctx, method, uri, attempt, time.Since(start), err)
+			span.SetAttribute("retries", attempt-1)
+			if err == nil {
+				span.SetAttribute("result_count", resultCount(data))
+			}
+			endSpan(span, err)
+			return err
+		}
+
+		if c.metrics != nil {
+			c.metrics.ObserveRetry(method, internal.Endpoint(uri))
+		}
+		if !sleep(ctx, c.retry.backoff(attempt, err)) {
+			err = internal.TransportError(ctx.Err())
+			c.logRequest(ctx, method, uri, attempt, time.Since(start), err)
+			span.SetAttribute("retries", attempt-1)
+			endSpan(span, err)
+			return err
+		}
+	}
+}
+
+//attempt builds and executes a single API request and decodes the response result into data
+func (c *Client) attempt(ctx context.Context, n int, method, uri string, query []internal.Query, payload []byte, data interface{}) (err *model.ResponseError) {
+	ctx, span := c.startSpan(ctx, "postcodes attempt "+method+" "+internal.Endpoint(uri))
+	span.SetAttribute("http.method", method)
+	span.SetAttribute("endpoint", internal.Endpoint(uri))
+	span.SetAttribute("attempt", n)
+	defer func() {
+		endSpan(span, err)
+	}()
+
+	if c.limiter != nil {
+		wait, err := c.limiter.Wait(ctx)
+		if err != nil {
+			return internal.TransportError(err)
+		}
+		if c.metrics != nil {
+			c.metrics.ObserveRateLimit(wait)
+		}
+	}
+
+	client := internal.NewClient(c.url, c.transport, c.headers)
+	client.Query = query
+	if spanContext := span.SpanContext(); spanContext.IsValid() {
+		client.Headers = append(client.Headers, internal.Header{Key: "traceparent", Value: spanContext.TraceParent()})
+	}
+	if err := client.RequestWithContext(ctx, method, uri, payload); err != nil {
+		return internal.RequestBuildError(err)
+	}
+
+	start := time.Now()
+	response, responseError := client.Do()
+	if c.metrics != nil {
+		status := 0
+		switch {
+		case responseError != nil:
+			status = responseError.Status
+		case response != nil:
+			status = response.StatusCode
+		}
+		c.metrics.ObserveRequest(method, internal.Endpoint(uri), status, time.Since(start))
+	}
+	if response != nil {
+		span.SetAttribute("http.status_code", response.StatusCode)
+	}
+	if responseError != nil {
+		return responseError
+	}
+	defer response.Body.Close()
+
+	return internal.ResponseDecoder(response.Body, data)
+}
+
+func filterQuery(filters []string) []internal.Query {
+	if len(filters) == 0 {
+		return nil
+	}
+	return []internal.Query{{Key: "filter", Value: strings.Join(filters, ",")}}
+}
+
+func validateLimit(limit *int64) *model.ResponseError {
+	if limit != nil && *limit > 100 {
+		return internal.ValidationError("Maximum limit exceeded! Limit must be less than 100")
+	}
+	return nil
+}
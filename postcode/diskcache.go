@@ -0,0 +1,493 @@
+package postcode
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"github.com/razorcorp/postcode-sdk-go/model"
+	"hash/fnv"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+type (
+	//DiskCache is a file backed Cache surviving restarts. Entries are spread across shard files in the cache
+	//directory. Every Set and Delete appends a record to the log of its shard, which is rewritten atomically once it
+	//holds twice as many records as live entries, on eviction and by Compact. Records appended shortly before a crash
+	//may be lost. A cache directory must not be shared by multiple processes.
+	DiskCache struct {
+		mu       sync.Mutex
+		dir      string
+		options  DiskCacheOptions
+		shards   map[int]map[string]diskEntry
+		records  map[int]int
+		encoders map[int]*recordEncoder
+		sizes    map[int]int64
+		stats    CacheStats
+		err      error
+	}
+
+	//DiskCacheOptions configures a DiskCache
+	DiskCacheOptions struct {
+		//Shards number of shard files. Defaults to 64
+		Shards int
+
+		//MaxBytes size limit of all shard files. The oldest entries are evicted once exceeded. Unlimited when 0
+		MaxBytes int64
+
+		//TTL maximum age of the entries regardless of their own expiry. Unlimited when 0
+		TTL time.Duration
+	}
+
+	diskEntry struct {
+		Entry  CacheEntry
+		Stored time.Time
+	}
+
+	//diskRecord is a record of a shard log, storing or deleting the entry of the key
+	diskRecord struct {
+		Key     string
+		Entry   diskEntry
+		Deleted bool
+	}
+
+	//recordEncoder encodes the records appended to a shard log by this process into a single gob stream, so that
+	//only its first record carries the type information
+	recordEncoder struct {
+		buffer  bytes.Buffer
+		encoder *gob.Encoder
+		started bool
+	}
+
+	//recordDecoder decodes the records of a shard log, starting a new gob stream where a record says so
+	recordDecoder struct {
+		reader  *bufio.Reader
+		buffer  bytes.Buffer
+		decoder *gob.Decoder
+	}
+)
+
+//record flags of a shard log, telling whether the record starts a new gob stream or continues the previous one
+const (
+	recordStart    byte = 1
+	recordContinue byte = 2
+)
+
+const (
+	//minCompactRecords number of records below which a shard log is never compacted on write
+	minCompactRecords = 64
+
+	//maxRecordSize size above which a shard log record is considered corrupt
+	maxRecordSize = 16 << 20
+)
+
+func init() {
+	gob.Register(&model.Postcode{})
+	gob.Register(&model.OutcodeData{})
+	gob.Register(&model.Place{})
+	gob.Register(&model.ScottishPostcodeData{})
+}
+
+//NewDiskCache returns a DiskCache storing its shard files in the given directory, creating it when missing
+func NewDiskCache(dir string, options DiskCacheOptions) (*DiskCache, error) {
+	if options.Shards <= 0 {
+		options.Shards = 64
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	d := &DiskCache{
+		dir:      dir,
+		options:  options,
+		shards:   make(map[int]map[string]diskEntry),
+		records:  make(map[int]int),
+		encoders: make(map[int]*recordEncoder),
+		sizes:    make(map[int]int64),
+	}
+	for shard := 0; shard < options.Shards; shard++ {
+		if info, err := os.Stat(d.path(shard)); err == nil {
+			d.sizes[shard] = info.Size()
+		}
+	}
+	return d, nil
+}
+
+//Get returns the entry stored for the key unless it has expired
+func (d *DiskCache) Get(key string) (CacheEntry, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	entries := d.load(d.shard(key))
+	stored, ok := entries[key]
+	if ok && d.expired(stored) {
+		ok = false
+	}
+	if !ok {
+		d.stats.Misses++
+		return CacheEntry{}, false
+	}
+
+	d.stats.Hits++
+	return stored.Entry, true
+}
+
+//Set stores the entry for the key and appends it to its shard log, evicting the oldest entries when the size limit
+//is exceeded. A failed write leaves the entry in memory only and is reported by Err.
+func (d *DiskCache) Set(key string, entry CacheEntry) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	shard := d.shard(key)
+	stored := diskEntry{Entry: entry, Stored: time.Now()}
+	d.load(shard)[key] = stored
+	if err := d.append(shard, diskRecord{Key: key, Entry: stored}); err != nil {
+		d.err = err
+		return
+	}
+	if err := d.evict(); err != nil {
+		d.err = err
+	}
+}
+
+//Delete removes the entry stored for the key
+func (d *DiskCache) Delete(key string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	shard := d.shard(key)
+	entries := d.load(shard)
+	if _, ok := entries[key]; !ok {
+		return
+	}
+	delete(entries, key)
+	if err := d.append(shard, diskRecord{Key: key, Deleted: true}); err != nil {
+		d.err = err
+	}
+}
+
+//Purge removes all entries and their shard files
+func (d *DiskCache) Purge() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for shard := 0; shard < d.options.Shards; shard++ {
+		if err := os.Remove(d.path(shard)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	d.shards = make(map[int]map[string]diskEntry)
+	d.records = make(map[int]int)
+	d.encoders = make(map[int]*recordEncoder)
+	d.sizes = make(map[int]int64)
+	return nil
+}
+
+//Compact rewrites the shard logs without expired, overwritten and deleted entries and enforces the size limit
+func (d *DiskCache) Compact() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for shard := 0; shard < d.options.Shards; shard++ {
+		entries := d.load(shard)
+		for key, stored := range entries {
+			if d.expired(stored) {
+				delete(entries, key)
+			}
+		}
+		if d.records[shard] > len(entries) {
+			if err := d.write(shard); err != nil {
+				return err
+			}
+		}
+	}
+	return d.evict()
+}
+
+//Stats returns the hit, miss and eviction counts of the cache. Entries counts the entries of loaded shards only.
+func (d *DiskCache) Stats() CacheStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	stats := d.stats
+	for _, entries := range d.shards {
+		stats.Entries += len(entries)
+	}
+	return stats
+}
+
+//Err returns the most recent error writing a shard file, if any
+func (d *DiskCache) Err() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.err
+}
+
+func (d *DiskCache) expired(stored diskEntry) bool {
+	if d.options.TTL > 0 && time.Since(stored.Stored) > d.options.TTL {
+		return true
+	}
+	return stored.Entry.expired()
+}
+
+func (d *DiskCache) shard(key string) int {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(key))
+	return int(hash.Sum32() % uint32(d.options.Shards))
+}
+
+func (d *DiskCache) path(shard int) string {
+	return filepath.Join(d.dir, fmt.Sprintf("shard-%03d.log", shard))
+}
+
+//load returns the entries of the shard, replaying the shard log on first use. The records following an unreadable
+//one, e.g. torn by a crash, are dropped and the log is rewritten without them.
+func (d *DiskCache) load(shard int) map[string]diskEntry {
+	if entries, ok := d.shards[shard]; ok {
+		return entries
+	}
+
+	entries := make(map[string]diskEntry)
+	records := 0
+	torn := false
+	if file, err := os.Open(d.path(shard)); err == nil {
+		decoder := &recordDecoder{reader: bufio.NewReader(file)}
+		for {
+			record, err := decoder.next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				torn = true
+				break
+			}
+			records++
+			if record.Deleted {
+				delete(entries, record.Key)
+			} else {
+				entries[record.Key] = record.Entry
+			}
+		}
+		_ = file.Close()
+	}
+	d.shards[shard] = entries
+	d.records[shard] = records
+	if torn {
+		if err := d.write(shard); err != nil {
+			d.err = err
+		}
+	}
+	return entries
+}
+
+//append appends the record to the shard log, rewriting the log instead once most of its records are stale
+func (d *DiskCache) append(shard int, record diskRecord) error {
+	if records := d.records[shard] + 1; records > minCompactRecords && records > 2*len(d.shards[shard]) {
+		return d.write(shard)
+	}
+
+	file, err := os.OpenFile(d.path(shard), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	encoder, ok := d.encoders[shard]
+	if !ok {
+		encoder = newRecordEncoder()
+		d.encoders[shard] = encoder
+	}
+	encoded, err := encoder.encode(record)
+	if err == nil {
+		_, err = file.Write(encoded)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		// the type information sent by the encoder may not have reached the log
+		delete(d.encoders, shard)
+		return err
+	}
+
+	d.records[shard]++
+	d.sizes[shard] += int64(len(encoded))
+	return nil
+}
+
+//write atomically replaces the shard log with a record per entry of the shard
+func (d *DiskCache) write(shard int) error {
+	entries := d.shards[shard]
+	if len(entries) == 0 {
+		delete(d.sizes, shard)
+		delete(d.records, shard)
+		delete(d.encoders, shard)
+		if err := os.Remove(d.path(shard)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	file, err := os.CreateTemp(d.dir, "shard-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	delete(d.encoders, shard)
+	encoder := newRecordEncoder()
+	writer := bufio.NewWriter(file)
+	for key, stored := range entries {
+		encoded, err := encoder.encode(diskRecord{Key: key, Entry: stored})
+		if err != nil {
+			_ = file.Close()
+			return err
+		}
+		if _, err := writer.Write(encoded); err != nil {
+			_ = file.Close()
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(file.Name(), d.path(shard)); err != nil {
+		return err
+	}
+
+	d.records[shard] = len(entries)
+	d.encoders[shard] = encoder
+	d.sizes[shard] = info.Size()
+	return nil
+}
+
+func newRecordEncoder() *recordEncoder {
+	e := new(recordEncoder)
+	e.encoder = gob.NewEncoder(&e.buffer)
+	return e
+}
+
+//encode returns the record gob encoded, prefixed with its flag and length
+func (e *recordEncoder) encode(record diskRecord) ([]byte, error) {
+	e.buffer.Reset()
+	if err := e.encoder.Encode(record); err != nil {
+		return nil, err
+	}
+
+	flag := recordContinue
+	if !e.started {
+		flag, e.started = recordStart, true
+	}
+	encoded := make([]byte, 0, 1+binary.MaxVarintLen64+e.buffer.Len())
+	encoded = append(encoded, flag)
+	encoded = binary.AppendUvarint(encoded, uint64(e.buffer.Len()))
+	return append(encoded, e.buffer.Bytes()...), nil
+}
+
+//next returns the next record of the shard log. Returns io.EOF at the end of the log
+func (r *recordDecoder) next() (diskRecord, error) {
+	var record diskRecord
+	flag, err := r.reader.ReadByte()
+	if err != nil {
+		return record, err
+	}
+	length, err := binary.ReadUvarint(r.reader)
+	if err != nil {
+		return record, io.ErrUnexpectedEOF
+	}
+	if length > maxRecordSize {
+		return record, fmt.Errorf("shard log record of %d bytes exceeds %d bytes", length, maxRecordSize)
+	}
+
+	switch {
+	case flag == recordStart:
+		r.buffer.Reset()
+		r.decoder = gob.NewDecoder(&r.buffer)
+	case flag != recordContinue || r.decoder == nil:
+		return record, fmt.Errorf("invalid shard log record flag %d", flag)
+	}
+	if _, err := io.CopyN(&r.buffer, r.reader, int64(length)); err != nil {
+		return record, io.ErrUnexpectedEOF
+	}
+	if err := r.decoder.Decode(&record); err != nil {
+		return record, err
+	}
+	if r.buffer.Len() > 0 {
+		return record, fmt.Errorf("shard log record has %d trailing bytes", r.buffer.Len())
+	}
+	return record, nil
+}
+
+//evict removes the oldest entries until the shard files fit into the size limit
+func (d *DiskCache) evict() error {
+	if d.options.MaxBytes <= 0 || d.size() <= d.options.MaxBytes {
+		return nil
+	}
+
+	type candidate struct {
+		shard  int
+		key    string
+		stored time.Time
+	}
+	var candidates []candidate
+	for shard := 0; shard < d.options.Shards; shard++ {
+		for key, stored := range d.load(shard) {
+			candidates = append(candidates, candidate{shard: shard, key: key, stored: stored.Stored})
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].stored.Before(candidates[j].stored)
+	})
+
+	// evict down to 90% of the limit so that every following write does not trigger another eviction
+	average := d.size() / int64(len(candidates))
+	if average < 1 {
+		average = 1
+	}
+	excess := d.size() - d.options.MaxBytes*9/10
+	count := int((excess + average - 1) / average)
+	if count > len(candidates) {
+		count = len(candidates)
+	}
+
+	changed := make(map[int]bool)
+	for _, evicted := range candidates[:count] {
+		delete(d.shards[evicted.shard], evicted.key)
+		changed[evicted.shard] = true
+		d.stats.Evictions++
+	}
+	for shard := range changed {
+		if err := d.write(shard); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *DiskCache) size() int64 {
+	var total int64
+	for _, size := range d.sizes {
+		total += size
+	}
+	return total
+}
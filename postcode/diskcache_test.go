@@ -0,0 +1,155 @@
+package postcode
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/razorcorp/postcode-sdk-go/model"
+)
+
+func TestDiskCacheSurvivesReopen(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewDiskCache(dir, DiskCacheOptions{Shards: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache.Set("postcodes/RG1 1AF", CacheEntry{Value: &model.Postcode{Postcode: "RG1 1AF"}})
+	cache.Set("postcodes/RG1 1AZ", CacheEntry{Value: &model.Postcode{Postcode: "RG1 1AZ"}})
+	cache.Set("postcodes/RG1 1AF", CacheEntry{Value: &model.Postcode{Postcode: "RG1 1AF", OutCode: "RG1"}})
+	cache.Set("postcodes/ZZ1 1ZZ", CacheEntry{Status: 404, Message: "Postcode not found"})
+	cache.Delete("postcodes/RG1 1AZ")
+	if err := cache.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := NewDiskCache(dir, DiskCacheOptions{Shards: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, ok := reopened.Get("postcodes/RG1 1AF")
+	if !ok || entry.Value.(*model.Postcode).OutCode != "RG1" {
+		t.Errorf("Get(RG1 1AF) = %+v, %v, want the overwritten entry", entry, ok)
+	}
+	if _, ok := reopened.Get("postcodes/RG1 1AZ"); ok {
+		t.Error("Get(RG1 1AZ) found a deleted entry")
+	}
+	if entry, ok := reopened.Get("postcodes/ZZ1 1ZZ"); !ok || entry.Status != 404 {
+		t.Errorf("Get(ZZ1 1ZZ) = %+v, %v, want the negative entry", entry, ok)
+	}
+}
+
+func TestDiskCacheTornRecord(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewDiskCache(dir, DiskCacheOptions{Shards: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache.Set("a", CacheEntry{Value: &model.Postcode{Postcode: "RG1 1AF"}})
+	cache.Set("b", CacheEntry{Value: &model.Postcode{Postcode: "RG1 1AZ"}})
+
+	// simulate a crash halfway through appending the second record
+	path := filepath.Join(dir, "shard-000.log")
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(path, info.Size()-10); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := NewDiskCache(dir, DiskCacheOptions{Shards: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := reopened.Get("a"); !ok {
+		t.Error("Get(a) lost the intact record")
+	}
+	if _, ok := reopened.Get("b"); ok {
+		t.Error("Get(b) found the torn record")
+	}
+	reopened.Set("c", CacheEntry{Value: &model.Postcode{Postcode: "RG1 2AG"}})
+
+	again, err := NewDiskCache(dir, DiskCacheOptions{Shards: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := again.Get(key); !ok {
+			t.Errorf("Get(%s) lost a record appended after the torn one", key)
+		}
+	}
+}
+
+func TestDiskCacheCompactsOverwrites(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewDiskCache(dir, DiskCacheOptions{Shards: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := CacheEntry{Value: &model.Postcode{Postcode: "RG1 1AF"}}
+	cache.Set("a", entry)
+	info, err := os.Stat(filepath.Join(dir, "shard-000.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := info.Size()
+	cache.Set("a", entry)
+	if info, err = os.Stat(filepath.Join(dir, "shard-000.log")); err != nil {
+		t.Fatal(err)
+	}
+	record := info.Size() - first
+
+	for i := 0; i < 10*minCompactRecords; i++ {
+		cache.Set("a", entry)
+	}
+	if info, err = os.Stat(filepath.Join(dir, "shard-000.log")); err != nil {
+		t.Fatal(err)
+	}
+	if limit := first + minCompactRecords*record; info.Size() > limit {
+		t.Errorf("shard log of a single entry grew to %d bytes, want at most %d", info.Size(), limit)
+	}
+}
+
+func TestDiskCacheSetsAppend(t *testing.T) {
+	cache, err := NewDiskCache(t.TempDir(), DiskCacheOptions{Shards: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	for i := 0; i < 20000; i++ {
+		cache.Set(cacheKey("postcodes", strconv.Itoa(i)), CacheEntry{Value: &model.Postcode{Postcode: "RG1 1AF"}})
+	}
+	if err := cache.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("20000 sets took %v, want the cost of a set independent of the number of entries", elapsed)
+	}
+	if stats := cache.Stats(); stats.Entries != 20000 {
+		t.Errorf("Entries = %d, want 20000", stats.Entries)
+	}
+}
+
+func TestDiskCacheEvictsOldest(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewDiskCache(dir, DiskCacheOptions{Shards: 2, MaxBytes: 8 << 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 200; i++ {
+		cache.Set(strconv.Itoa(i), CacheEntry{Value: &model.Postcode{Postcode: "RG1 1AF"}})
+	}
+	if stats := cache.Stats(); stats.Evictions == 0 {
+		t.Error("Evictions = 0, want the size limit enforced")
+	}
+	if err := cache.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	if size := cache.size(); size > 8<<10 {
+		t.Errorf("size = %d, want at most %d", size, 8<<10)
+	}
+}
@@ -0,0 +1,206 @@
+package postcode
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"github.com/razorcorp/postcode-sdk-go/model"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+//enrichLinger time a partial batch waits for more postcodes before it is looked up
+const enrichLinger = 50 * time.Millisecond
+
+type (
+	//Result is the outcome of enriching a single postcode of a stream
+	Result struct {
+		//Input postcode as received from the stream
+		Input string
+
+		//Normalised postcode sent to the API
+		Normalised string
+
+		//Postcode matching postcode data. nil when the postcode is not found or the lookup failed
+		Postcode *model.Postcode
+
+		//Err reason the postcode could not be enriched
+		Err *model.ResponseError
+	}
+)
+
+//Enrich Looks up the postcodes received from the given channel in bulk lookups of up to 100 postcodes.
+//
+//The results are sent in input order. Batches are sent once full, or once no further postcode arrived for a short
+//while. Up to the configured concurrency of batches are in flight at once; reading the input pauses until the
+//results are consumed, bounding the memory used by the pipeline.
+//
+//The returned channel is closed once the input channel is closed and all results are sent, or once the context is
+//done.
+func (c *Client) Enrich(ctx context.Context, postcodes <-chan string) <-chan Result {
+	ctx, span := c.startOperation(ctx, "Enrich", 0)
+	batches := make(chan chan []Result, c.parallelism())
+	out := make(chan Result, maxBulkSize)
+
+	go c.enrichBatches(ctx, postcodes, batches)
+
+	go func() {
+		sent := 0
+		defer func() { endStreamOperation(span, sent) }()
+		defer close(out)
+		for batch := range batches {
+			var results []Result
+			select {
+			case results = <-batch:
+			case <-ctx.Done():
+				return
+			}
+			for _, result := range results {
+				select {
+				case out <- result:
+					sent++
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
+
+//EnrichReader is like Enrich but reads one postcode per line from the given reader. Blank lines are skipped. A
+//failure reading the input is reported as the last result.
+func (c *Client) EnrichReader(ctx context.Context, reader io.Reader) <-chan Result {
+	ctx, span := c.startOperation(ctx, "EnrichReader", 0)
+	postcodes := make(chan string)
+	results := c.Enrich(ctx, postcodes)
+	out := make(chan Result)
+
+	var readErr error
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(postcodes)
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			select {
+			case postcodes <- line:
+			case <-ctx.Done():
+				return
+			}
+		}
+		readErr = scanner.Err()
+	}()
+
+	go func() {
+		sent := 0
+		defer func() { endStreamOperation(span, sent) }()
+		defer close(out)
+		for result := range results {
+			select {
+			case out <- result:
+				sent++
+			case <-ctx.Done():
+				return
+			}
+		}
+		wg.Wait()
+		if readErr != nil {
+			select {
+			case out <- Result{Err: &model.ResponseError{
+				Message: fmt.Sprintf("Failed to read input: %s", readErr.Error()),
+				Kind:    model.ErrRequest,
+				Err:     readErr,
+			}}:
+				sent++
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return out
+}
+
+//enrichBatches collects the postcodes into batches and looks each batch up in its own goroutine. The channels
+//receiving the batch results are queued in input order; the capacity of the queue bounds the batches in flight.
+func (c *Client) enrichBatches(ctx context.Context, postcodes <-chan string, batches chan<- chan []Result) {
+	defer close(batches)
+
+	var batch []string
+	timer := time.NewTimer(enrichLinger)
+	defer timer.Stop()
+
+	flush := func() bool {
+		if len(batch) == 0 {
+			return true
+		}
+		done := make(chan []Result, 1)
+		select {
+		case batches <- done:
+		case <-ctx.Done():
+			return false
+		}
+		go func(batch []string) {
+			done <- c.enrichBatch(ctx, batch)
+		}(batch)
+		batch = nil
+		return true
+	}
+
+	for {
+		select {
+		case postcode, ok := <-postcodes:
+			if !ok {
+				flush()
+				return
+			}
+			if len(batch) == 0 {
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+				timer.Reset(enrichLinger)
+			}
+			batch = append(batch, postcode)
+			if len(batch) == maxBulkSize && !flush() {
+				return
+			}
+		case <-timer.C:
+			if !flush() {
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+//enrichBatch looks up a single batch of up to maxBulkSize postcodes
+func (c *Client) enrichBatch(ctx context.Context, batch []string) []Result {
+	normalised := make([]string, len(batch))
+	for i, postcode := range batch {
+		normalised[i] = normalise(postcode)
+	}
+
+	ctx, skipped := withUnanswered(ctx)
+	data, err := c.BulkLookupContext(ctx, Postcodes{Postcodes: normalised}, nil)
+	results := make([]Result, len(batch))
+	for i, postcode := range batch {
+		lookup := lookupResult(normalised[i], data, i, err, skipped)
+		results[i] = Result{
+			Input:      postcode,
+			Normalised: normalised[i],
+			Postcode:   lookup.Postcode,
+			Err:        lookup.Error,
+		}
+	}
+	return results
+}
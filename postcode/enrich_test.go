@@ -0,0 +1,195 @@
+package postcode_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/razorcorp/postcode-sdk-go/model"
+	"github.com/razorcorp/postcode-sdk-go/postcode"
+	"github.com/razorcorp/postcode-sdk-go/postcode/postcodetest"
+)
+
+func TestEnrichOrder(t *testing.T) {
+	fake, postcodes := newSectorFake(t, "RG4 7", 350)
+	// batches complete out of order
+	fake.Inject(postcodetest.Fault{Kind: postcodetest.FaultLatency, Method: http.MethodPost, Latency: time.Millisecond, Jitter: 30 * time.Millisecond})
+	client := fake.Client(postcode.WithConcurrency(4))
+
+	var inputs []string
+	for i := len(postcodes) - 1; i >= 0; i-- {
+		input := postcodes[i].Postcode
+		if i%2 == 0 {
+			input = strings.ToLower(strings.ReplaceAll(input, " ", ""))
+		}
+		inputs = append(inputs, input)
+		if i == 200 {
+			inputs = append(inputs, "RG4 7ZZ", "not a postcode")
+		}
+	}
+
+	in := make(chan string)
+	go func() {
+		defer close(in)
+		for _, input := range inputs {
+			in <- input
+		}
+	}()
+
+	i := 0
+	for result := range client.Enrich(context.Background(), in) {
+		if i >= len(inputs) {
+			t.Fatalf("result %d = %+v, want %d results", i, result, len(inputs))
+		}
+		if result.Input != inputs[i] {
+			t.Fatalf("result %d = %+v, want the result of %q", i, result, inputs[i])
+		}
+		switch result.Input {
+		case "RG4 7ZZ":
+			if result.Postcode != nil || !errors.Is(result.Err, model.ErrNotFound) {
+				t.Errorf("result %d = %+v, want not found", i, result)
+			}
+		case "not a postcode":
+			if result.Normalised != "NOTAPOSTCODE" || !errors.Is(result.Err, model.ErrInvalidPostcode) {
+				t.Errorf("result %d = %+v, want invalid", i, result)
+			}
+		default:
+			if result.Err != nil || result.Postcode.Postcode != result.Normalised || strings.ReplaceAll(result.Normalised, " ", "") != strings.ToUpper(strings.ReplaceAll(result.Input, " ", "")) {
+				t.Errorf("result %d = %+v, want the postcode of %q", i, result, result.Input)
+			}
+		}
+		i++
+	}
+	if i != len(inputs) {
+		t.Errorf("results = %d, want %d", i, len(inputs))
+	}
+}
+
+func TestEnrichBackPressure(t *testing.T) {
+	fake, postcodes := newSectorFake(t, "RG4 7", 100)
+	client := fake.Client(postcode.WithConcurrency(1))
+
+	const total = 2000
+	var read int32
+	in := make(chan string)
+	go func() {
+		defer close(in)
+		for i := 0; i < total; i++ {
+			in <- postcodes[i%len(postcodes)].Postcode
+			atomic.AddInt32(&read, 1)
+		}
+	}()
+	out := client.Enrich(context.Background(), in)
+
+	// the output buffer, the batch being sent, one queued, and one being collected
+	time.Sleep(300 * time.Millisecond)
+	if got := atomic.LoadInt32(&read); got > 500 {
+		t.Errorf("postcodes read while no result was consumed = %d, want reading paused", got)
+	}
+
+	received := 0
+	for result := range out {
+		if result.Err != nil || result.Postcode.Postcode != postcodes[received%len(postcodes)].Postcode {
+			t.Fatalf("result %d = %+v", received, result)
+		}
+		received++
+	}
+	if received != total {
+		t.Errorf("results = %d, want %d", received, total)
+	}
+}
+
+func TestEnrichReaderErrors(t *testing.T) {
+	fake := newFake(t)
+	client := fake.Client()
+
+	input := io.MultiReader(
+		strings.NewReader("RG1 1AF\n\n  rg122pe  \nRG1 9ZZ\nnot a postcode\n\nEH1 1YZ\n"),
+		iotest.ErrReader(errors.New("disk failure")),
+	)
+	var results []postcode.Result
+	for result := range client.EnrichReader(context.Background(), input) {
+		results = append(results, result)
+	}
+
+	want := []struct {
+		input, postcode string
+		err             error
+	}{
+		{"RG1 1AF", "RG1 1AF", nil},
+		{"rg122pe", "RG12 2PE", nil},
+		{"RG1 9ZZ", "", model.ErrNotFound},
+		{"not a postcode", "", model.ErrInvalidPostcode},
+		{"EH1 1YZ", "EH1 1YZ", nil},
+		{"", "", model.ErrRequest},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("EnrichReader() = %+v, want %d results", results, len(want))
+	}
+	for i, want := range want {
+		result := results[i]
+		if result.Input != want.input {
+			t.Errorf("result %d input = %q, want %q", i, result.Input, want.input)
+		}
+		if want.err == nil && (result.Err != nil || result.Postcode.Postcode != want.postcode) {
+			t.Errorf("result %d = %+v, want %s", i, result, want.postcode)
+		}
+		if want.err != nil && (result.Postcode != nil || !errors.Is(result.Err, want.err)) {
+			t.Errorf("result %d error = %v, want %v", i, result.Err, want.err)
+		}
+	}
+	if err := results[len(results)-1].Err; err == nil || !strings.Contains(err.Message, "disk failure") {
+		t.Errorf("last result error = %v, want the read failure", err)
+	}
+}
+
+func TestEnrichReaderFailedBatch(t *testing.T) {
+	fake, postcodes := newSectorFake(t, "RG4 7", 150)
+	fake.Inject(postcodetest.Fault{Kind: postcodetest.FaultStatus, Method: http.MethodPost, Times: 1, Status: http.StatusBadGateway})
+	client := fake.Client(postcode.WithConcurrency(1))
+
+	var lines strings.Builder
+	for _, p := range postcodes {
+		fmt.Fprintln(&lines, p.Postcode)
+	}
+
+	var results []postcode.Result
+	for result := range client.EnrichReader(context.Background(), strings.NewReader(lines.String())) {
+		results = append(results, result)
+	}
+	if len(results) != len(postcodes) {
+		t.Fatalf("results = %d, want %d", len(results), len(postcodes))
+	}
+
+	// either batch may be sent first, and fail
+	failed := 0
+	for _, batch := range [][]postcode.Result{results[:100], results[100:]} {
+		batchFailed := batch[0].Err != nil
+		if batchFailed {
+			failed++
+		}
+		for _, result := range batch {
+			if batchFailed && (result.Postcode != nil || !errors.Is(result.Err, model.ErrServer)) {
+				t.Errorf("result %+v of the failed batch, want a server error", result)
+			}
+			if !batchFailed && (result.Err != nil || result.Postcode.Postcode != result.Input) {
+				t.Errorf("result %+v, want the postcode of %s", result, result.Input)
+			}
+		}
+	}
+	if failed != 1 {
+		t.Errorf("failed batches = %d, want 1", failed)
+	}
+	for i, result := range results {
+		if result.Input != postcodes[i].Postcode {
+			t.Errorf("result %d input = %q, want %q", i, result.Input, postcodes[i].Postcode)
+		}
+	}
+}
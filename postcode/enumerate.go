@@ -0,0 +1,143 @@
+package postcode
+
+import (
+	"context"
+	"github.com/razorcorp/postcode-sdk-go/model"
+	"github.com/razorcorp/postcode-sdk-go/postcode/internal"
+	"sort"
+	"strings"
+)
+
+//enumerateLimit number of postcodes requested per Autocomplete call of an enumeration. A prefix returning this many
+//postcodes may have more and is expanded further.
+const enumerateLimit = 100
+
+//unitLetters letters used in the unit of the inward code
+const unitLetters = "ABDEFGHJLNPQRSTUWXYZ"
+
+type (
+	//EnumerateState is the progress of an enumeration. Keep it to resume an interrupted enumeration.
+	EnumerateState struct {
+		//Pending prefixes still to be expanded
+		Pending []string `json:"pending"`
+
+		//Found postcodes found so far
+		Found []string `json:"found"`
+	}
+)
+
+//Enumerate Returns every live postcode of the given outcode (e.g. "RG12") or sector (e.g. "RG12 2") in sorted order.
+//
+//Autocomplete returns up to 100 postcodes, so prefixes returning the limit are expanded recursively
+//(RG12 → RG12 0 … RG12 9 → RG12 2A … RG12 2Z) until every branch returns fewer postcodes than the limit.
+func (c *Client) Enumerate(ctx context.Context, prefix string) (result []string, err *model.ResponseError) {
+	ctx, span := c.startOperation(ctx, "Enumerate", 0)
+	defer func() { endOperation(span, result, err) }()
+
+	state := new(EnumerateState)
+	if err := c.EnumerateFunc(ctx, prefix, state, nil); err != nil {
+		return nil, err
+	}
+
+	sort.Strings(state.Found)
+	return state.Found, nil
+}
+
+//EnumerateFunc is like Enumerate but calls fn with each postcode as soon as it is found, in no particular order.
+//
+//The progress is recorded in the given state. If the enumeration fails or the context is done, calling EnumerateFunc
+//again with the same state resumes it without reporting the postcodes found before.
+func (c *Client) EnumerateFunc(ctx context.Context, prefix string, state *EnumerateState, fn func(postcode string)) (err *model.ResponseError) {
+	if state == nil {
+		state = new(EnumerateState)
+	}
+	ctx, span := c.startOperation(ctx, "EnumerateFunc", 0)
+	defer func() { endOperation(span, state.Found, err) }()
+	if len(state.Pending) == 0 && len(state.Found) == 0 {
+		root, err := enumerationRoot(prefix)
+		if err != nil {
+			return err
+		}
+		state.Pending = []string{root}
+	}
+
+	seen := make(map[string]bool, len(state.Found))
+	for _, postcode := range state.Found {
+		seen[postcode] = true
+	}
+
+	limit := int64(enumerateLimit)
+	for len(state.Pending) > 0 {
+		if err := ctx.Err(); err != nil {
+			return internal.TransportError(err)
+		}
+
+		next := state.Pending[len(state.Pending)-1]
+		results, err := c.AutocompleteContext(ctx, next, &limit)
+		if err != nil {
+			return err
+		}
+		state.Pending = state.Pending[:len(state.Pending)-1]
+
+		if len(results) >= enumerateLimit {
+			children := expand(next)
+			for i := len(children) - 1; i >= 0; i-- {
+				state.Pending = append(state.Pending, children[i])
+			}
+		}
+
+		for _, postcode := range results {
+			if !strings.HasPrefix(postcode, next) || seen[postcode] {
+				continue
+			}
+			seen[postcode] = true
+			state.Found = append(state.Found, postcode)
+			if fn != nil {
+				fn(postcode)
+			}
+		}
+	}
+	return nil
+}
+
+//enumerationRoot returns the canonical prefix of the given outcode or sector, e.g. "RG12 " or "RG12 2"
+func enumerationRoot(prefix string) (string, *model.ResponseError) {
+	fields := strings.Fields(strings.ToUpper(prefix))
+	switch {
+	case len(fields) == 1:
+		if outcode, err := parseOutcode(fields[0]); err == nil {
+			return outcode + " ", nil
+		}
+		code := fields[0]
+		if last := len(code) - 1; last > 0 && code[last] >= '0' && code[last] <= '9' {
+			if outcode, err := parseOutcode(code[:last]); err == nil {
+				return outcode + " " + code[last:], nil
+			}
+		}
+	case len(fields) == 2 && len(fields[1]) == 1 && fields[1][0] >= '0' && fields[1][0] <= '9':
+		if outcode, err := parseOutcode(fields[0]); err == nil {
+			return outcode + " " + fields[1], nil
+		}
+	}
+	return "", invalidPostcode("Invalid outcode or sector")
+}
+
+//expand returns the prefixes one character longer than the given canonical prefix
+func expand(prefix string) []string {
+	incode := prefix[strings.IndexByte(prefix, ' ')+1:]
+	switch len(incode) {
+	case 0:
+		children := make([]string, 0, 10)
+		for digit := '0'; digit <= '9'; digit++ {
+			children = append(children, prefix+string(digit))
+		}
+		return children
+	case 1, 2:
+		children := make([]string, 0, len(unitLetters))
+		for _, letter := range unitLetters {
+			children = append(children, prefix+string(letter))
+		}
+		return children
+	}
+	return nil
+}
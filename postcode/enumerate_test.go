@@ -0,0 +1,123 @@
+package postcode_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/razorcorp/postcode-sdk-go/model"
+	"github.com/razorcorp/postcode-sdk-go/postcode"
+	"github.com/razorcorp/postcode-sdk-go/postcode/postcodetest"
+)
+
+//newEnumerationFake returns a fake server holding 250 postcodes in sector RG4 7, more than a single Autocomplete call
+//returns, and 30 in sector RG4 8, with all of them sorted
+func newEnumerationFake(t *testing.T) (*postcodetest.Server, []string) {
+	t.Helper()
+	fake, postcodes := newSectorFake(t, "RG4 7", 250)
+	postcodes = append(postcodes, sectorPostcodes("RG4 8", 30)...)
+	fake.Seed(postcodetest.Dataset{Postcodes: postcodes})
+
+	codes := make([]string, len(postcodes))
+	for i := range postcodes {
+		codes[i] = postcodes[i].Postcode
+	}
+	sort.Strings(codes)
+	return fake, codes
+}
+
+func TestEnumerateExpandsFullPrefixes(t *testing.T) {
+	for _, test := range []struct {
+		prefix string
+		match  string
+		calls  int
+	}{
+		// RG4 is full, so is RG4 7 among RG4 0 … RG4 9, but none of RG4 7A … RG4 7Z
+		{"RG4", "RG4 ", 1 + 10 + 20},
+		{"rg4 7", "RG4 7", 1 + 20},
+		{"RG4 8", "RG4 8", 1},
+	} {
+		t.Run(test.prefix, func(t *testing.T) {
+			fake, all := newEnumerationFake(t)
+			client := fake.Client()
+
+			postcodes, err := client.Enumerate(context.Background(), test.prefix)
+			if err != nil {
+				t.Fatalf("Enumerate() error = %v", err)
+			}
+			var want []string
+			for _, code := range all {
+				if strings.HasPrefix(code, test.match) {
+					want = append(want, code)
+				}
+			}
+			if !reflect.DeepEqual(postcodes, want) {
+				t.Errorf("Enumerate() = %d postcodes, want the %d of %q sorted", len(postcodes), len(want), test.match)
+			}
+			if calls := fake.Calls(http.MethodGet, "postcodes/:postcode/autocomplete"); calls != test.calls {
+				t.Errorf("Autocomplete calls = %d, want %d", calls, test.calls)
+			}
+		})
+	}
+}
+
+func TestEnumerateInvalidPrefix(t *testing.T) {
+	client := newFake(t).Client()
+	for _, prefix := range []string{"", "RG", "RG4 7A", "not an outcode"} {
+		if _, err := client.Enumerate(context.Background(), prefix); !errors.Is(err, model.ErrInvalidPostcode) {
+			t.Errorf("Enumerate(%q) error = %v, want invalid", prefix, err)
+		}
+	}
+}
+
+func TestEnumerateFuncResume(t *testing.T) {
+	fake, all := newEnumerationFake(t)
+	client := fake.Client()
+	fake.Inject(postcodetest.Fault{
+		Kind:     postcodetest.FaultStatus,
+		Endpoint: "postcodes/:postcode/autocomplete",
+		After:    5,
+		Times:    1,
+		Status:   http.StatusServiceUnavailable,
+	})
+
+	var reported []string
+	report := func(postcode string) { reported = append(reported, postcode) }
+	state := new(postcode.EnumerateState)
+	if err := client.EnumerateFunc(context.Background(), "RG4", state, report); !errors.Is(err, model.ErrServer) {
+		t.Fatalf("EnumerateFunc() error = %v, want the injected failure", err)
+	}
+	if len(state.Pending) == 0 || len(state.Found) == 0 || len(state.Found) != len(reported) {
+		t.Fatalf("state after the failure = %d pending, %d found, %d reported, want progress recorded", len(state.Pending), len(state.Found), len(reported))
+	}
+
+	// resumes from the state as it would be kept between runs
+	saved, err := json.Marshal(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resumed := new(postcode.EnumerateState)
+	if err := json.Unmarshal(saved, resumed); err != nil {
+		t.Fatal(err)
+	}
+	before := len(reported)
+	if err := client.EnumerateFunc(context.Background(), "RG4", resumed, report); err != nil {
+		t.Fatalf("EnumerateFunc() resumed error = %v", err)
+	}
+
+	sort.Strings(reported)
+	if !reflect.DeepEqual(reported, all) {
+		t.Errorf("reported %d postcodes, %d before the failure, want each of the %d once", len(reported), before, len(all))
+	}
+	if len(resumed.Pending) != 0 || len(resumed.Found) != len(all) {
+		t.Errorf("resumed state = %d pending, %d found, want the enumeration complete", len(resumed.Pending), len(resumed.Found))
+	}
+	if calls := fake.Calls(http.MethodGet, "postcodes/:postcode/autocomplete"); calls != 1+10+20+1 {
+		t.Errorf("Autocomplete calls = %d, want only the failed call repeated", calls)
+	}
+}
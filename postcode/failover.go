@@ -0,0 +1,372 @@
+package postcode
+
+import (
+	"context"
+	"errors"
+	"github.com/razorcorp/postcode-sdk-go/model"
+	"sync"
+)
+
+const (
+	//PreferOnline asks postcodes.io first and falls back to the local dataset when the API is unreachable, times out
+	//or responds with a 5xx status
+	PreferOnline Preference = "online"
+
+	//PreferLocal asks the local dataset first and postcodes.io for anything the dataset does not know, e.g. postcodes
+	//introduced after its release
+	PreferLocal Preference = "local"
+)
+
+type (
+	//Preference which of postcodes.io and the local dataset a failover client asks first
+	Preference string
+
+	//failover answers from postcodes.io and a local dataset in the order of its preference
+	failover struct {
+		online     Backend
+		local      Backend
+		preference Preference
+	}
+
+	//apiBackend answers from the postcodes.io API of the client
+	apiBackend struct {
+		client *Client
+	}
+
+	//vintaged is implemented by datasets reporting their release, e.g. onspd.Dataset
+	vintaged interface {
+		Vintage() string
+	}
+
+	//unanswered postcodes left null by the bulk lookups of a context because their online lookup failed, with the
+	//error of that lookup. They must neither be cached nor reported as not found.
+	unanswered struct {
+		mu     sync.Mutex
+		errors map[string]*model.ResponseError
+	}
+
+	//unansweredKey context key of the unanswered postcodes of the bulk lookups sent with the context
+	unansweredKey struct{}
+)
+
+//WithFailover answers Lookup, BulkLookup, Validation, Autocomplete, Query, OutcodeLookup and
+//TerminatedPostcodeLookup from both postcodes.io and the given local dataset, e.g. an onspd.Dataset, asking first the
+//one of the given preference.
+//
+//Postcodes, outcodes and terminated postcodes answered by the local dataset carry a model.Fallback mark with the
+//vintage of the dataset, and are not cached. Validation and Autocomplete results cannot be marked. When neither
+//answers, the error of postcodes.io is returned; LookupAll reports it for each postcode a bulk lookup left null, and
+//these postcodes are not cached as not found. The Lookup calls sent to postcodes.io are batched by WithBatching.
+func WithFailover(local Backend, preference Preference) Option {
+	return func(c *Client) {
+		c.backend = &failover{
+			online:     apiBackend{client: c},
+			local:      local,
+			preference: preference,
+		}
+	}
+}
+
+func (f *failover) Lookup(ctx context.Context, postcode string) (*model.Postcode, *model.ResponseError) {
+	if f.preference == PreferLocal {
+		if data, err := f.local.Lookup(ctx, postcode); err == nil {
+			return f.markPostcode(data), nil
+		}
+		return f.online.Lookup(ctx, postcode)
+	}
+
+	data, err := f.online.Lookup(ctx, postcode)
+	if !unavailable(ctx, err) {
+		return data, err
+	}
+	local, localErr := f.local.Lookup(ctx, postcode)
+	if localErr != nil {
+		return nil, err
+	}
+	return f.markPostcode(local), nil
+}
+
+func (f *failover) BulkLookup(ctx context.Context, postcodes []string) ([]model.Postcodes, *model.ResponseError) {
+	if f.preference == PreferLocal {
+		return f.bulkLookupLocal(ctx, postcodes)
+	}
+
+	data, err := f.online.BulkLookup(ctx, postcodes)
+	if !unavailable(ctx, err) {
+		return data, err
+	}
+	local, localErr := f.local.BulkLookup(ctx, postcodes)
+	if localErr != nil {
+		return nil, err
+	}
+	mark := f.mark()
+	for i := range local {
+		if local[i].Postcode.Postcode == "" {
+			leaveUnanswered(ctx, postcodes[i], err)
+			continue
+		}
+		local[i].Postcode.Fallback = mark
+	}
+	return local, nil
+}
+
+//bulkLookupLocal answers the postcodes from the local dataset and looks up only the unknown ones online. When the
+//online lookup fails the unknown postcodes are left null, and its error is returned only if the dataset knew none.
+func (f *failover) bulkLookupLocal(ctx context.Context, postcodes []string) ([]model.Postcodes, *model.ResponseError) {
+	data, err := f.local.BulkLookup(ctx, postcodes)
+	if err != nil || len(data) != len(postcodes) {
+		return f.online.BulkLookup(ctx, postcodes)
+	}
+
+	mark := f.mark()
+	var unknown []string
+	var unknownIndex []int
+	for i := range data {
+		if data[i].Postcode.Postcode == "" {
+			unknown = append(unknown, postcodes[i])
+			unknownIndex = append(unknownIndex, i)
+			continue
+		}
+		data[i].Postcode.Fallback = mark
+	}
+	if len(unknown) == 0 {
+		return data, nil
+	}
+
+	results, err := f.online.BulkLookup(ctx, unknown)
+	if err != nil {
+		if len(unknown) == len(postcodes) {
+			return nil, err
+		}
+		for _, postcode := range unknown {
+			leaveUnanswered(ctx, postcode, err)
+		}
+		return data, nil
+	}
+	for j, i := range unknownIndex {
+		if j < len(results) {
+			data[i].Postcode = results[j].Postcode
+		}
+	}
+	return data, nil
+}
+
+func (f *failover) Validation(ctx context.Context, postcode string) (bool, *model.ResponseError) {
+	if f.preference == PreferLocal {
+		if valid, err := f.local.Validation(ctx, postcode); err == nil && valid {
+			return true, nil
+		}
+		return f.online.Validation(ctx, postcode)
+	}
+
+	valid, err := f.online.Validation(ctx, postcode)
+	if !unavailable(ctx, err) {
+		return valid, err
+	}
+	valid, localErr := f.local.Validation(ctx, postcode)
+	if localErr != nil {
+		return false, err
+	}
+	return valid, nil
+}
+
+func (f *failover) Autocomplete(ctx context.Context, postcode string, limit int) ([]string, *model.ResponseError) {
+	if f.preference == PreferLocal {
+		if data, err := f.local.Autocomplete(ctx, postcode, limit); err == nil && len(data) > 0 {
+			return data, nil
+		}
+		return f.online.Autocomplete(ctx, postcode, limit)
+	}
+
+	data, err := f.online.Autocomplete(ctx, postcode, limit)
+	if !unavailable(ctx, err) {
+		return data, err
+	}
+	local, localErr := f.local.Autocomplete(ctx, postcode, limit)
+	if localErr != nil {
+		return nil, err
+	}
+	return local, nil
+}
+
+func (f *failover) Query(ctx context.Context, query string, limit int) ([]model.Postcode, *model.ResponseError) {
+	if f.preference == PreferLocal {
+		if data, err := f.local.Query(ctx, query, limit); err == nil && len(data) > 0 {
+			return f.markPostcodes(data), nil
+		}
+		return f.online.Query(ctx, query, limit)
+	}
+
+	data, err := f.online.Query(ctx, query, limit)
+	if !unavailable(ctx, err) {
+		return data, err
+	}
+	local, localErr := f.local.Query(ctx, query, limit)
+	if localErr != nil {
+		return nil, err
+	}
+	return f.markPostcodes(local), nil
+}
+
+func (f *failover) OutcodeLookup(ctx context.Context, outcode string) (*model.OutcodeData, *model.ResponseError) {
+	if f.preference == PreferLocal {
+		if data, err := f.local.OutcodeLookup(ctx, outcode); err == nil {
+			return f.markOutcode(data), nil
+		}
+		return f.online.OutcodeLookup(ctx, outcode)
+	}
+
+	data, err := f.online.OutcodeLookup(ctx, outcode)
+	if !unavailable(ctx, err) {
+		return data, err
+	}
+	local, localErr := f.local.OutcodeLookup(ctx, outcode)
+	if localErr != nil {
+		return nil, err
+	}
+	return f.markOutcode(local), nil
+}
+
+func (f *failover) TerminatedPostcodeLookup(ctx context.Context, postcode string) (*model.TerminatedPostcode, *model.ResponseError) {
+	if f.preference == PreferLocal {
+		if data, err := f.local.TerminatedPostcodeLookup(ctx, postcode); err == nil {
+			return f.markTerminated(data), nil
+		}
+		return f.online.TerminatedPostcodeLookup(ctx, postcode)
+	}
+
+	data, err := f.online.TerminatedPostcodeLookup(ctx, postcode)
+	if !unavailable(ctx, err) {
+		return data, err
+	}
+	local, localErr := f.local.TerminatedPostcodeLookup(ctx, postcode)
+	if localErr != nil {
+		return nil, err
+	}
+	return f.markTerminated(local), nil
+}
+
+//mark returns the fallback mark of the answers of the local dataset
+func (f *failover) mark() *model.Fallback {
+	mark := new(model.Fallback)
+	if dataset, ok := f.local.(vintaged); ok {
+		mark.Vintage = dataset.Vintage()
+	}
+	return mark
+}
+
+//markPostcode returns a marked copy of the given postcode, leaving the one of the local dataset untouched
+func (f *failover) markPostcode(data *model.Postcode) *model.Postcode {
+	marked := *data
+	marked.Fallback = f.mark()
+	return &marked
+}
+
+func (f *failover) markPostcodes(data []model.Postcode) []model.Postcode {
+	mark := f.mark()
+	marked := make([]model.Postcode, len(data))
+	for i := range data {
+		marked[i] = data[i]
+		marked[i].Fallback = mark
+	}
+	return marked
+}
+
+func (f *failover) markOutcode(data *model.OutcodeData) *model.OutcodeData {
+	marked := *data
+	marked.Fallback = f.mark()
+	return &marked
+}
+
+func (f *failover) markTerminated(data *model.TerminatedPostcode) *model.TerminatedPostcode {
+	marked := *data
+	marked.Fallback = f.mark()
+	return &marked
+}
+
+//unavailable reports whether the given error of postcodes.io calls for the local dataset: a transport error, such as
+//an unreachable host or a timeout, or a 5xx response. Errors after the given context ended do not.
+func unavailable(ctx context.Context, err *model.ResponseError) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	return errors.Is(err, model.ErrTransport) || errors.Is(err, model.ErrServer)
+}
+
+//withUnanswered returns a context collecting the postcodes left unanswered by the bulk lookups sent with it, keeping
+//the collection of the given context if it has one
+func withUnanswered(ctx context.Context) (context.Context, *unanswered) {
+	if collected, ok := ctx.Value(unansweredKey{}).(*unanswered); ok {
+		return ctx, collected
+	}
+	collected := &unanswered{errors: make(map[string]*model.ResponseError)}
+	return context.WithValue(ctx, unansweredKey{}, collected), collected
+}
+
+//leaveUnanswered records the given postcode as left null because of the given error of its online lookup, if the
+//context collects unanswered postcodes
+func leaveUnanswered(ctx context.Context, postcode string, err *model.ResponseError) {
+	collected, ok := ctx.Value(unansweredKey{}).(*unanswered)
+	if !ok {
+		return
+	}
+	collected.mu.Lock()
+	defer collected.mu.Unlock()
+	collected.errors[postcode] = err
+}
+
+//error returns the error the given postcode was left unanswered for, or nil if it was answered
+func (u *unanswered) error(postcode string) *model.ResponseError {
+	if u == nil {
+		return nil
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.errors[postcode]
+}
+
+//fromFallback reports whether the given result was served by the local dataset of a failover client
+func fromFallback(value interface{}) bool {
+	switch data := value.(type) {
+	case *model.Postcode:
+		return data.Fallback != nil
+	case *model.OutcodeData:
+		return data.Fallback != nil
+	case *model.TerminatedPostcode:
+		return data.Fallback != nil
+	}
+	return false
+}
+
+func (a apiBackend) Lookup(ctx context.Context, postcode string) (*model.Postcode, *model.ResponseError) {
+	if a.client.batcher != nil {
+		return a.client.batcher.lookup(ctx, postcode)
+	}
+	return a.client.apiLookup(ctx, postcode)
+}
+
+func (a apiBackend) BulkLookup(ctx context.Context, postcodes []string) ([]model.Postcodes, *model.ResponseError) {
+	return a.client.apiBulkLookup(ctx, Postcodes{Postcodes: postcodes}, nil)
+}
+
+func (a apiBackend) Validation(ctx context.Context, postcode string) (bool, *model.ResponseError) {
+	return a.client.apiValidation(ctx, postcode)
+}
+
+func (a apiBackend) Autocomplete(ctx context.Context, postcode string, limit int) ([]string, *model.ResponseError) {
+	limit64 := int64(limit)
+	return a.client.apiAutocomplete(ctx, postcode, &limit64)
+}
+
+func (a apiBackend) Query(ctx context.Context, query string, limit int) ([]model.Postcode, *model.ResponseError) {
+	limit64 := int64(limit)
+	return a.client.apiQuery(ctx, query, &limit64)
+}
+
+func (a apiBackend) OutcodeLookup(ctx context.Context, outcode string) (*model.OutcodeData, *model.ResponseError) {
+	return a.client.apiOutcodeLookup(ctx, outcode)
+}
+
+func (a apiBackend) TerminatedPostcodeLookup(ctx context.Context, postcode string) (*model.TerminatedPostcode, *model.ResponseError) {
+	return a.client.apiTerminatedPostcodeLookup(ctx, postcode)
+}
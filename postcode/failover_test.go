@@ -0,0 +1,178 @@
+package postcode_test
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/razorcorp/postcode-sdk-go/model"
+	"github.com/razorcorp/postcode-sdk-go/postcode"
+	"github.com/razorcorp/postcode-sdk-go/postcode/onspd"
+	"github.com/razorcorp/postcode-sdk-go/postcode/postcodetest"
+)
+
+func openDataset(t *testing.T) *onspd.Dataset {
+	t.Helper()
+	dataset, err := onspd.Open("onspd/testdata/ONSPD_sample.csv", onspd.Options{Vintage: "2024-05"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dataset
+}
+
+func newFake(t *testing.T) *postcodetest.Server {
+	t.Helper()
+	fake := postcodetest.NewServer(postcodetest.SampleDataset())
+	t.Cleanup(fake.Close)
+	return fake
+}
+
+func TestFailoverBatching(t *testing.T) {
+	fake := newFake(t)
+	client := fake.Client(
+		postcode.WithFailover(openDataset(t), postcode.PreferOnline),
+		postcode.WithBatching(50*time.Millisecond, 100),
+	)
+
+	postcodes := []string{"RG1 1AF", "RG1 1AZ", "SW1A 1AA", "EH1 1YZ"}
+	results := make([]*model.Postcode, len(postcodes))
+	errs := make([]*model.ResponseError, len(postcodes))
+	var wg sync.WaitGroup
+	for i := range postcodes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = client.Lookup(postcodes[i])
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range postcodes {
+		if errs[i] != nil || results[i].Postcode != postcodes[i] || results[i].Fallback != nil {
+			t.Errorf("Lookup(%q) = %+v, %v, want the answer of postcodes.io", postcodes[i], results[i], errs[i])
+		}
+	}
+	if calls := fake.Calls(http.MethodGet, "postcodes/:postcode"); calls != 0 {
+		t.Errorf("GET calls = %d, want the lookups batched", calls)
+	}
+	if calls := fake.Calls(http.MethodPost, "postcodes"); calls != 1 {
+		t.Errorf("POST calls = %d, want 1", calls)
+	}
+}
+
+func TestFailoverPreferOnline(t *testing.T) {
+	fake := newFake(t)
+	client := fake.Client(postcode.WithFailover(openDataset(t), postcode.PreferOnline))
+
+	data, err := client.Lookup("RG1 1AF")
+	if err != nil || data.Fallback != nil {
+		t.Errorf("Lookup() = %+v, %v, want the answer of postcodes.io", data, err)
+	}
+	if _, err := client.Lookup("ZE3 9JZ"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("Lookup() of a postcode unknown to postcodes.io error = %v, want not found without falling back", err)
+	}
+
+	fake.Inject(postcodetest.Fault{Kind: postcodetest.FaultStatus, Status: http.StatusServiceUnavailable})
+	data, err = client.Lookup("RG1 1AF")
+	if err != nil || data.Postcode != "RG1 1AF" || data.Fallback == nil || data.Fallback.Vintage != "2024-05" {
+		t.Errorf("Lookup() while postcodes.io fails = %+v, %v, want the dataset answer marked 2024-05", data, err)
+	}
+	if _, err := client.Lookup("SW1A 2AA"); !errors.Is(err, model.ErrServer) {
+		t.Errorf("Lookup() unknown to the dataset error = %v, want the error of postcodes.io", err)
+	}
+
+	bulk, err := client.BulkLookup(postcode.Postcodes{Postcodes: []string{"RG1 1AF", "SW1A 2AA"}}, nil)
+	if err != nil || len(bulk) != 2 {
+		t.Fatalf("BulkLookup() while postcodes.io fails = %v, %v", bulk, err)
+	}
+	if bulk[0].Postcode.Postcode != "RG1 1AF" || bulk[0].Postcode.Fallback == nil || bulk[1].Postcode != (model.Postcode{}) {
+		t.Errorf("BulkLookup() while postcodes.io fails = %+v, want RG1 1AF from the dataset and a null result", bulk)
+	}
+	all := client.LookupAll([]string{"RG1 1AF", "SW1A 2AA"}, nil)
+	if all[0].Error != nil || all[0].Postcode.Fallback == nil {
+		t.Errorf("LookupAll() while postcodes.io fails result 0 = %+v, want the dataset answer", all[0])
+	}
+	if all[1].Postcode != nil || !errors.Is(all[1].Error, model.ErrServer) {
+		t.Errorf("LookupAll() while postcodes.io fails result 1 = %+v, want the error of postcodes.io", all[1])
+	}
+
+	fake.ClearFaults()
+	fake.Inject(postcodetest.Fault{Kind: postcodetest.FaultReset})
+	outcode, err := client.OutcodeLookup("RG1")
+	if err != nil || outcode.Outcode != "RG1" || outcode.Fallback == nil {
+		t.Errorf("OutcodeLookup() while postcodes.io is unreachable = %+v, %v, want the dataset answer", outcode, err)
+	}
+}
+
+func TestFailoverPreferLocal(t *testing.T) {
+	fake := newFake(t)
+	client := fake.Client(
+		postcode.WithFailover(openDataset(t), postcode.PreferLocal),
+		postcode.WithCache(postcode.NewMemoryCache(100), postcode.DefaultCacheTTL),
+	)
+
+	data, err := client.Lookup("RG1 1AF")
+	if err != nil || data.Fallback == nil || data.Fallback.Vintage != "2024-05" {
+		t.Errorf("Lookup() = %+v, %v, want the dataset answer marked 2024-05", data, err)
+	}
+	if calls := fake.Calls("", ""); calls != 0 {
+		t.Errorf("calls = %d, want the dataset to answer alone", calls)
+	}
+	data, err = client.Lookup("SW1A 2AA")
+	if err != nil || data.Postcode != "SW1A 2AA" || data.Fallback != nil {
+		t.Errorf("Lookup() unknown to the dataset = %+v, %v, want the answer of postcodes.io", data, err)
+	}
+
+	postcodes := postcode.Postcodes{Postcodes: []string{"RG1 1AZ", "sw1a2aa", "RG1 9ZZ", "EH1 1YZ"}}
+	bulk, err := client.BulkLookup(postcodes, nil)
+	if err != nil || len(bulk) != 4 {
+		t.Fatalf("BulkLookup() = %v, %v", bulk, err)
+	}
+	for i, want := range []string{"RG1 1AZ", "SW1A 2AA", "", "EH1 1YZ"} {
+		if bulk[i].Postcode.Postcode != want || (bulk[i].Postcode.Fallback != nil) != (i == 0 || i == 3) {
+			t.Errorf("BulkLookup() result %d = %+v, want %q", i, bulk[i].Postcode, want)
+		}
+	}
+	if calls := fake.Calls(http.MethodPost, "postcodes"); calls != 1 {
+		t.Errorf("POST calls = %d, want only the unknown postcodes sent", calls)
+	}
+}
+
+func TestFailoverPreferLocalOnlineFails(t *testing.T) {
+	fake := newFake(t)
+	client := fake.Client(
+		postcode.WithFailover(openDataset(t), postcode.PreferLocal),
+		postcode.WithCache(postcode.NewMemoryCache(100), postcode.DefaultCacheTTL),
+	)
+	fake.Inject(postcodetest.Fault{Kind: postcodetest.FaultStatus, Status: http.StatusServiceUnavailable})
+
+	postcodes := postcode.Postcodes{Postcodes: []string{"RG1 1AF", "SW1A 2AA", "RG1 1AZ"}}
+	bulk, err := client.BulkLookup(postcodes, nil)
+	if err != nil || len(bulk) != 3 {
+		t.Fatalf("BulkLookup() while postcodes.io fails = %v, %v, want the answers of the dataset", bulk, err)
+	}
+	for i, want := range []string{"RG1 1AF", "", "RG1 1AZ"} {
+		if bulk[i].Postcode.Postcode != want || (bulk[i].Postcode.Fallback != nil) != (want != "") {
+			t.Errorf("BulkLookup() result %d = %+v, want %q", i, bulk[i].Postcode, want)
+		}
+	}
+	all := client.LookupAll(postcodes.Postcodes, nil)
+	if all[1].Postcode != nil || !errors.Is(all[1].Error, model.ErrServer) || errors.Is(all[1].Error, model.ErrNotFound) {
+		t.Errorf("LookupAll() while postcodes.io fails result 1 = %+v, want the error of postcodes.io", all[1])
+	}
+	if all[0].Error != nil || all[2].Error != nil {
+		t.Errorf("LookupAll() while postcodes.io fails = %+v, want the answers of the dataset", all)
+	}
+
+	if _, err := client.BulkLookup(postcode.Postcodes{Postcodes: []string{"SW1A 2AA", "RG1 9ZZ"}}, nil); !errors.Is(err, model.ErrServer) {
+		t.Errorf("BulkLookup() unknown to the dataset error = %v, want the error of postcodes.io", err)
+	}
+
+	fake.ClearFaults()
+	bulk, err = client.BulkLookup(postcodes, nil)
+	if err != nil || bulk[1].Postcode.Postcode != "SW1A 2AA" {
+		t.Errorf("BulkLookup() once postcodes.io recovered = %v, %v, want SW1A 2AA not cached as not found", bulk, err)
+	}
+}
@@ -2,10 +2,14 @@ package internal
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/razorcorp/postcode-sdk-go/model"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 /**
@@ -25,46 +29,69 @@ type (
 	Header key
 	Query  key
 	client struct {
-		Url     string
-		Headers []Header
-		Query   []Query
-		req     http.Request
+		Url        string
+		Headers    []Header
+		Query      []Query
+		HttpClient Doer
+		req        http.Request
 	}
 
+	//Doer executes HTTP requests. Implemented by *http.Client, and exposed as postcode.Doer
+	Doer interface {
+		Do(req *http.Request) (*http.Response, error)
+	}
+
+	//Http builds and executes a single API request
 	Http interface {
-		Do() (responses []http.Response, error *model.ResponseError)
-		Request(method, uri string, payload []byte) error
+		Do() (*http.Response, *model.ResponseError)
+		RequestWithContext(ctx context.Context, method, uri string, payload []byte) error
 	}
 )
 
-func Client() *client {
+var _ Http = (*client)(nil)
+
+//NewClient returns a request client for the given base URL.
+//
+//	url: Base URL of the API, e.g. https://api.postcodes.io
+//
+//	httpClient: Doer used to execute the request, e.g. an *http.Client
+//
+//	headers: (optional) Additional headers sent with the request. Overrides the default headers
+func NewClient(url string, httpClient Doer, headers []Header) *client {
 	return &client{
-		Url: API,
-		Headers: []Header{
+		Url: url,
+		Headers: append([]Header{
 			{Key: "Content-Type", Value: "application/json"},
 			{Key: "Accept", Value: "application/json"},
-		},
+		}, headers...),
+		HttpClient: httpClient,
 	}
 }
 
 func (c *client) Do() (responses *http.Response, error *model.ResponseError) {
-	htClient := new(http.Client)
-	resp, err := htClient.Do(&c.req)
+	resp, err := c.HttpClient.Do(&c.req)
+	if errors.Is(err, model.ErrRequest) {
+		return nil, RefusedError(err)
+	}
 	if err != nil {
-		return nil, &model.ResponseError{
-			Status: http.StatusInternalServerError,
-			Error:  err.Error(),
-		}
+		return nil, TransportError(err)
 	}
 
 	if resp.StatusCode != 200 {
+		defer resp.Body.Close()
 		errorResponse := new(model.ResponseError)
 		if decodeErr := json.NewDecoder(resp.Body).Decode(&errorResponse); decodeErr != nil {
 			return nil, &model.ResponseError{
-				Status: http.StatusInternalServerError,
-				Error:  decodeErr.Error(),
+				Status:     resp.StatusCode,
+				Message:    http.StatusText(resp.StatusCode),
+				Err:        decodeErr,
+				RetryAfter: retryAfter(resp.Header.Get("Retry-After")),
 			}
 		}
+		if errorResponse.Status == 0 {
+			errorResponse.Status = resp.StatusCode
+		}
+		errorResponse.RetryAfter = retryAfter(resp.Header.Get("Retry-After"))
 
 		return nil, errorResponse
 	}
@@ -72,14 +99,16 @@ func (c *client) Do() (responses *http.Response, error *model.ResponseError) {
 	return resp, nil
 }
 
-func (c *client) Request(method, uri string, payload []byte) error {
-	req, err := http.NewRequest(method, fmt.Sprintf("%s/%s", c.Url, uri), bytes.NewBuffer(payload))
+//RequestWithContext builds the request carrying the given context. The request and reading of the response body
+//are aborted once the context is cancelled or its deadline is exceeded.
+func (c *client) RequestWithContext(ctx context.Context, method, uri string, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, method, fmt.Sprintf("%s/%s", c.Url, uri), bytes.NewBuffer(payload))
 	if err != nil {
 		return err
 	}
 
 	for _, header := range c.Headers {
-		req.Header.Add(header.Key, header.Value)
+		req.Header.Set(header.Key, header.Value)
 	}
 
 	params := req.URL.Query()
@@ -91,3 +120,19 @@ func (c *client) Request(method, uri string, payload []byte) error {
 	c.req = *req
 	return nil
 }
+
+//retryAfter parses the Retry-After header value given either as delay in seconds or as HTTP date
+func retryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if delay := time.Until(date); delay > 0 {
+			return delay
+		}
+	}
+	return 0
+}
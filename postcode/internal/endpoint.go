@@ -0,0 +1,32 @@
+package internal
+
+import "strings"
+
+//pathParameters path segments following which the next segment is a postcode, outcode or place code, and their
+//placeholders
+var pathParameters = map[string]string{
+	"postcodes":            ":postcode",
+	"terminated_postcodes": ":postcode",
+	"outcodes":             ":outcode",
+	"places":               ":place",
+}
+
+//ReplacePathParameters returns the given request path, without leading and trailing slashes, with its postcode,
+//outcode and place code segments replaced with the result of replace, given the segment and its placeholder
+func ReplacePathParameters(path string, replace func(segment, placeholder string) string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := 1; i < len(segments); i++ {
+		if placeholder, ok := pathParameters[segments[i-1]]; ok {
+			segments[i] = replace(segments[i], placeholder)
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+//Endpoint returns the given request path with its postcode, outcode and place code segments replaced with
+//placeholders, e.g. postcodes/:postcode/validate
+func Endpoint(path string) string {
+	return ReplacePathParameters(path, func(_, placeholder string) string {
+		return placeholder
+	})
+}
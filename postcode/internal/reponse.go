@@ -2,10 +2,10 @@ package internal
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/razorcorp/postcode-sdk-go/model"
 	"io"
-	"log"
 	"net/http"
 )
 
@@ -26,26 +26,55 @@ type (
 
 func RequestBuildError(err error) *model.ResponseError {
 	return &model.ResponseError{
-		Status: http.StatusInternalServerError,
-		Error:  fmt.Sprintf("Failed to build request: %s", err.Error()),
+		Message: fmt.Sprintf("Failed to build request: %s", err.Error()),
+		Kind:    model.ErrRequest,
+		Err:     err,
 	}
 }
 
 func PayloadEncodeError(err error) *model.ResponseError {
 	return &model.ResponseError{
-		Status: http.StatusInternalServerError,
-		Error:  fmt.Sprintf("Failed to encode the request body: %s", err.Error()),
+		Message: fmt.Sprintf("Failed to encode the request body: %s", err.Error()),
+		Kind:    model.ErrRequest,
+		Err:     err,
 	}
 }
 
 func ResponseDecodeError(err error) *model.ResponseError {
 	return &model.ResponseError{
-		Status: http.StatusInternalServerError,
-		Error:  fmt.Sprintf("Failed to parse response body: %s", err.Error()),
+		Message: fmt.Sprintf("Failed to parse response body: %s", err.Error()),
+		Kind:    model.ErrDecode,
+		Err:     err,
 	}
 }
 
-//ResponseDecoder transpose given HTTP response body into the given interface
+func TransportError(err error) *model.ResponseError {
+	return &model.ResponseError{
+		Message: fmt.Sprintf("Failed to execute request: %s", err.Error()),
+		Kind:    model.ErrTransport,
+		Err:     err,
+	}
+}
+
+func RefusedError(err error) *model.ResponseError {
+	return &model.ResponseError{
+		Message: fmt.Sprintf("Request refused: %s", err.Error()),
+		Kind:    model.ErrRequest,
+		Err:     err,
+	}
+}
+
+func ValidationError(message string) *model.ResponseError {
+	return &model.ResponseError{
+		Status:  http.StatusBadRequest,
+		Message: message,
+		Kind:    model.ErrValidation,
+	}
+}
+
+//ResponseDecoder transpose given HTTP response body into the given interface. Failing to read the body is a
+//transport error, unless the body refused to be read with an error wrapping model.ErrRequest, and failing to parse it
+//a decode error.
 //
 //Parameters:
 //
@@ -53,15 +82,23 @@ func ResponseDecodeError(err error) *model.ResponseError {
 //
 //	model: interface pointer for a Go struct
 func ResponseDecoder(body io.Reader, iface interface{}) *model.ResponseError {
+	raw, err := io.ReadAll(body)
+	if errors.Is(err, model.ErrRequest) {
+		return RefusedError(err)
+	}
+	if err != nil {
+		return TransportError(err)
+	}
+
 	data := responseWrapper{}
-	if err := json.NewDecoder(body).Decode(&data); err != nil {
+	if err := json.Unmarshal(raw, &data); err != nil {
 		return ResponseDecodeError(err)
 	}
 
 	if data.Status >= 400 {
 		return &model.ResponseError{
-			Status: data.Status,
-			Error:  data.Error,
+			Status:  data.Status,
+			Message: data.Error,
 		}
 	}
 
@@ -71,7 +108,6 @@ func ResponseDecoder(body io.Reader, iface interface{}) *model.ResponseError {
 	}
 
 	if err := json.Unmarshal(jsonObject, iface); err != nil {
-		log.Printf("%#v", err)
 		return ResponseDecodeError(err)
 	}
 
@@ -0,0 +1,120 @@
+package postcode
+
+import (
+	"context"
+	"errors"
+	"github.com/razorcorp/postcode-sdk-go/model"
+	"github.com/razorcorp/postcode-sdk-go/postcode/internal"
+	"log/slog"
+	"net/url"
+	"time"
+)
+
+type (
+	//LogOptions configures the records written by the logger of a Client
+	LogOptions struct {
+		//Level of the records of successful requests and cache lookups. Defaults to slog.LevelDebug
+		Level slog.Leveler
+
+		//ErrorLevel of the records of failed requests. Defaults to slog.LevelWarn
+		ErrorLevel slog.Leveler
+
+		//ShowPostcodes logs the postcodes and outcodes of the requests. By default they are replaced with a
+		//placeholder, e.g. postcodes/:postcode, as postcodes may be personal data.
+		ShowPostcodes bool
+	}
+
+	//batchSizeKey context key of the number of items sent by a bulk request
+	batchSizeKey struct{}
+)
+
+//WithLogger logs every request made by the Client with its method, endpoint, status, latency, retry count and batch
+//size, as well as cache hits and misses, to the given structured logger
+func WithLogger(logger *slog.Logger, options LogOptions) Option {
+	return func(c *Client) {
+		if options.Level == nil {
+			options.Level = slog.LevelDebug
+		}
+		if options.ErrorLevel == nil {
+			options.ErrorLevel = slog.LevelWarn
+		}
+		c.logger = logger
+		c.logOptions = options
+	}
+}
+
+//logRequest logs the outcome of a request sent with the given number of attempts
+func (c *Client) logRequest(ctx context.Context, method, uri string, attempts int, latency time.Duration, err *model.ResponseError) {
+	if c.logger == nil {
+		return
+	}
+
+	level := c.logOptions.Level.Level()
+	status := 200
+	if err != nil {
+		level = c.logOptions.ErrorLevel.Level()
+		status = err.Status
+	}
+	if !c.logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", method),
+		slog.String("endpoint", c.logEndpoint(uri)),
+		slog.Int("status", status),
+		slog.Duration("latency", latency),
+		slog.Int("retries", attempts-1),
+	}
+	if size := batchSize(ctx); size > 0 {
+		attrs = append(attrs, slog.Int("batch_size", size))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", c.logError(err)))
+	}
+	c.logger.LogAttrs(ctx, level, "postcodes request", attrs...)
+}
+
+//logCache logs the outcome of a cache lookup of the given cache key
+func (c *Client) logCache(ctx context.Context, key string, hits, size int) {
+	if c.logger == nil {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("endpoint", c.logEndpoint(key)),
+		slog.Bool("cache_hit", hits == size),
+	}
+	if size > 1 {
+		attrs = append(attrs, slog.Int("batch_size", size), slog.Int("cache_hits", hits))
+	}
+	c.logger.LogAttrs(ctx, c.logOptions.Level.Level(), "postcodes cache", attrs...)
+}
+
+//logEndpoint returns the endpoint of the given request URI with the postcodes redacted unless configured otherwise
+func (c *Client) logEndpoint(uri string) string {
+	if c.logOptions.ShowPostcodes {
+		return uri
+	}
+	return internal.Endpoint(uri)
+}
+
+//logError returns the message of the given error. Transport errors carry the request URL, which is left out unless
+//the postcodes are shown.
+func (c *Client) logError(err *model.ResponseError) string {
+	var urlErr *url.Error
+	if !c.logOptions.ShowPostcodes && errors.As(err, &urlErr) {
+		return "Failed to execute request: " + urlErr.Err.Error()
+	}
+	return err.Message
+}
+
+//withBatchSize returns a context recording the number of items sent by a bulk request
+func withBatchSize(ctx context.Context, size int) context.Context {
+	return context.WithValue(ctx, batchSizeKey{}, size)
+}
+
+//batchSize returns the number of items sent by the bulk request of the given context, or 0
+func batchSize(ctx context.Context) int {
+	size, _ := ctx.Value(batchSizeKey{}).(int)
+	return size
+}
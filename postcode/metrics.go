@@ -0,0 +1,34 @@
+package postcode
+
+import (
+	"time"
+)
+
+type (
+	//Metrics receives measurements of the requests made by a Client, e.g. to export them to a monitoring system. The
+	//endpoints are given with their postcodes replaced with placeholders, e.g. postcodes/:postcode, to keep the
+	//number of distinct values low. Implementations must be safe for concurrent use.
+	//
+	//The metrics package provides Prometheus and expvar implementations.
+	Metrics interface {
+		//ObserveRequest is called after each HTTP request attempt with the response status, or 0 if no response was
+		//received, and the time taken to receive the response
+		ObserveRequest(method, endpoint string, status int, latency time.Duration)
+
+		//ObserveRetry is called before a failed request is retried
+		ObserveRetry(method, endpoint string)
+
+		//ObserveCache is called after each cache lookup of a postcode, outcode or place
+		ObserveCache(endpoint string, hit bool)
+
+		//ObserveRateLimit is called once the rate limiter admits a request, with the time the request waited
+		ObserveRateLimit(wait time.Duration)
+	}
+)
+
+//WithMetrics reports the requests, retries, cache lookups and rate limiter waits of the Client to the given metrics
+func WithMetrics(metrics Metrics) Option {
+	return func(c *Client) {
+		c.metrics = metrics
+	}
+}
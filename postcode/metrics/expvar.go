@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"expvar"
+	"github.com/razorcorp/postcode-sdk-go/postcode"
+	"strconv"
+	"time"
+)
+
+var _ postcode.Metrics = (*Expvar)(nil)
+
+type (
+	//Expvar publishes the measurements of a postcode.Client as expvar variables, served as JSON on /debug/vars by the
+	//expvar package. Expvar is safe for concurrent use.
+	//
+	//	requests                 requests by "method endpoint status", status 0 for transport errors
+	//	request_seconds          total request latency by "method endpoint"
+	//	retries                  retried requests by "method endpoint"
+	//	cache_hits               cache hits by endpoint
+	//	cache_misses             cache misses by endpoint
+	//	rate_limit_requests      requests admitted by the rate limiter
+	//	rate_limit_throttled     requests delayed by the rate limiter
+	//	rate_limit_wait_seconds  time spent waiting for the rate limiter
+	Expvar struct {
+		requests       *expvar.Map
+		requestSeconds *expvar.Map
+		retries        *expvar.Map
+		cacheHits      *expvar.Map
+		cacheMisses    *expvar.Map
+		admitted       *expvar.Int
+		throttled      *expvar.Int
+		waited         *expvar.Float
+	}
+)
+
+//NewExpvar returns an Expvar publishing the metrics under the given name. Like expvar.Publish it panics if the name
+//is already in use, so create a single Expvar per name.
+func NewExpvar(name string) *Expvar {
+	e := &Expvar{
+		requests:       new(expvar.Map).Init(),
+		requestSeconds: new(expvar.Map).Init(),
+		retries:        new(expvar.Map).Init(),
+		cacheHits:      new(expvar.Map).Init(),
+		cacheMisses:    new(expvar.Map).Init(),
+		admitted:       new(expvar.Int),
+		throttled:      new(expvar.Int),
+		waited:         new(expvar.Float),
+	}
+
+	vars := expvar.NewMap(name)
+	vars.Set("requests", e.requests)
+	vars.Set("request_seconds", e.requestSeconds)
+	vars.Set("retries", e.retries)
+	vars.Set("cache_hits", e.cacheHits)
+	vars.Set("cache_misses", e.cacheMisses)
+	vars.Set("rate_limit_requests", e.admitted)
+	vars.Set("rate_limit_throttled", e.throttled)
+	vars.Set("rate_limit_wait_seconds", e.waited)
+	return e
+}
+
+func (e *Expvar) ObserveRequest(method, endpoint string, status int, latency time.Duration) {
+	e.requests.Add(method+" "+endpoint+" "+strconv.Itoa(status), 1)
+	e.requestSeconds.AddFloat(method+" "+endpoint, latency.Seconds())
+}
+
+func (e *Expvar) ObserveRetry(method, endpoint string) {
+	e.retries.Add(method+" "+endpoint, 1)
+}
+
+func (e *Expvar) ObserveCache(endpoint string, hit bool) {
+	if hit {
+		e.cacheHits.Add(endpoint, 1)
+		return
+	}
+	e.cacheMisses.Add(endpoint, 1)
+}
+
+func (e *Expvar) ObserveRateLimit(wait time.Duration) {
+	e.admitted.Add(1)
+	if wait > 0 {
+		e.throttled.Add(1)
+		e.waited.Add(wait.Seconds())
+	}
+}
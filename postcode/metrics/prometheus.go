@@ -0,0 +1,194 @@
+//Package metrics provides implementations of postcode.Metrics exporting the measurements of a postcode.Client
+package metrics
+
+import (
+	"fmt"
+	"github.com/razorcorp/postcode-sdk-go/postcode"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var _ postcode.Metrics = (*Prometheus)(nil)
+
+//DefaultBuckets upper bounds in seconds of the request latency histogram buckets
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type (
+	//Prometheus collects the measurements of a postcode.Client and renders them in the Prometheus text exposition
+	//format. Serve it as an http.Handler, e.g. on /metrics, to have it scraped. Prometheus is safe for concurrent use.
+	//
+	//	postcodes_requests_total{method,endpoint,status}           requests by response status, 0 for transport errors
+	//	postcodes_request_duration_seconds{method,endpoint}        request latency histogram
+	//	postcodes_retries_total{method,endpoint}                   retried requests
+	//	postcodes_cache_requests_total{endpoint,result}            cache lookups by result, hit or miss
+	//	postcodes_rate_limit_requests_total                        requests admitted by the rate limiter
+	//	postcodes_rate_limit_throttled_total                       requests delayed by the rate limiter
+	//	postcodes_rate_limit_wait_seconds_total                    time spent waiting for the rate limiter
+	Prometheus struct {
+		buckets []float64
+
+		mu        sync.Mutex
+		requests  map[string]float64
+		durations map[string]*histogram
+		retries   map[string]float64
+		cache     map[string]float64
+		admitted  float64
+		throttled float64
+		waited    float64
+	}
+
+	//histogram cumulative bucket counts, sum and count of observations
+	histogram struct {
+		counts []float64
+		sum    float64
+		count  float64
+	}
+)
+
+//NewPrometheus returns a Prometheus collector using the given latency histogram buckets in seconds. DefaultBuckets
+//are used when none are given.
+func NewPrometheus(buckets ...float64) *Prometheus {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &Prometheus{
+		buckets:   buckets,
+		requests:  make(map[string]float64),
+		durations: make(map[string]*histogram),
+		retries:   make(map[string]float64),
+		cache:     make(map[string]float64),
+	}
+}
+
+func (p *Prometheus) ObserveRequest(method, endpoint string, status int, latency time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.requests[labels("method", method, "endpoint", endpoint, "status", strconv.Itoa(status))]++
+
+	key := labels("method", method, "endpoint", endpoint)
+	h, ok := p.durations[key]
+	if !ok {
+		h = &histogram{counts: make([]float64, len(p.buckets))}
+		p.durations[key] = h
+	}
+	seconds := latency.Seconds()
+	for i, bound := range p.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+func (p *Prometheus) ObserveRetry(method, endpoint string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.retries[labels("method", method, "endpoint", endpoint)]++
+}
+
+func (p *Prometheus) ObserveCache(endpoint string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cache[labels("endpoint", endpoint, "result", result)]++
+}
+
+func (p *Prometheus) ObserveRateLimit(wait time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.admitted++
+	if wait > 0 {
+		p.throttled++
+		p.waited += wait.Seconds()
+	}
+}
+
+//ServeHTTP renders the metrics in the Prometheus text exposition format
+func (p *Prometheus) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	p.WriteTo(w)
+}
+
+//WriteTo writes the metrics in the Prometheus text exposition format to the given writer
+func (p *Prometheus) WriteTo(w io.Writer) (int64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var b strings.Builder
+	writeCounters(&b, "postcodes_requests_total", "Requests to postcodes.io by endpoint and response status.", p.requests)
+
+	b.WriteString("# HELP postcodes_request_duration_seconds Latency of the requests to postcodes.io.\n")
+	b.WriteString("# TYPE postcodes_request_duration_seconds histogram\n")
+	keys := make([]string, 0, len(p.durations))
+	for key := range p.durations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		h := p.durations[key]
+		for i, bound := range p.buckets {
+			fmt.Fprintf(&b, "postcodes_request_duration_seconds_bucket{%s,le=\"%s\"} %s\n", key, formatFloat(bound), formatFloat(h.counts[i]))
+		}
+		fmt.Fprintf(&b, "postcodes_request_duration_seconds_bucket{%s,le=\"+Inf\"} %s\n", key, formatFloat(h.count))
+		fmt.Fprintf(&b, "postcodes_request_duration_seconds_sum{%s} %s\n", key, formatFloat(h.sum))
+		fmt.Fprintf(&b, "postcodes_request_duration_seconds_count{%s} %s\n", key, formatFloat(h.count))
+	}
+
+	writeCounters(&b, "postcodes_retries_total", "Retried requests to postcodes.io.", p.retries)
+	writeCounters(&b, "postcodes_cache_requests_total", "Cache lookups by result, hit or miss.", p.cache)
+	writeCounter(&b, "postcodes_rate_limit_requests_total", "Requests admitted by the rate limiter.", p.admitted)
+	writeCounter(&b, "postcodes_rate_limit_throttled_total", "Requests delayed by the rate limiter.", p.throttled)
+	writeCounter(&b, "postcodes_rate_limit_wait_seconds_total", "Time spent waiting for the rate limiter.", p.waited)
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+func writeCounters(b *strings.Builder, name, help string, values map[string]float64) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+	for _, key := range sortedKeys(values) {
+		fmt.Fprintf(b, "%s{%s} %s\n", name, key, formatFloat(values[key]))
+	}
+}
+
+func writeCounter(b *strings.Builder, name, help string, value float64) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s counter\n%s %s\n", name, help, name, name, formatFloat(value))
+}
+
+//labelEscaper escapes label values as the text exposition format requires: only backslash, double quote and line feed
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+//labels renders the given label names and values, e.g. method="GET",endpoint="postcodes"
+func labels(pairs ...string) string {
+	rendered := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		rendered = append(rendered, fmt.Sprintf(`%s="%s"`, pairs[i], labelEscaper.Replace(pairs[i+1])))
+	}
+	return strings.Join(rendered, ",")
+}
+
+func sortedKeys(values map[string]float64) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLabelsEscaping(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "postcodes", want: `endpoint="postcodes"`},
+		{value: `back\slash`, want: `endpoint="back\\slash"`},
+		{value: `"quoted"`, want: `endpoint="\"quoted\""`},
+		{value: "line\nfeed", want: `endpoint="line\nfeed"`},
+		{value: "tab\tand é", want: "endpoint=\"tab\tand é\""},
+	}
+	for _, test := range tests {
+		if got := labels("endpoint", test.value); got != test.want {
+			t.Errorf("labels(%q) = %s, want %s", test.value, got, test.want)
+		}
+	}
+}
+
+func TestPrometheusWriteTo(t *testing.T) {
+	p := NewPrometheus()
+	p.ObserveRetry("GET", "places/é")
+	p.ObserveCache("postcodes", true)
+
+	var b strings.Builder
+	if _, err := p.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`postcodes_retries_total{method="GET",endpoint="places/é"} 1`,
+		`postcodes_cache_requests_total{endpoint="postcodes",result="hit"} 1`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("WriteTo() output misses %s:\n%s", want, b.String())
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package postcode
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/razorcorp/postcode-sdk-go/model"
+	"github.com/razorcorp/postcode-sdk-go/postcode/internal"
+	"io"
+	"net/http"
+)
+
+//defaultRequestIDHeader header carrying the request ID set by the RequestID middleware
+const defaultRequestIDHeader = "X-Request-ID"
+
+//defaultHTTPClient HTTP client shared by the clients configured without an HTTP client or Doer
+var defaultHTTPClient = new(http.Client)
+
+type (
+	//Doer executes HTTP requests on behalf of a Client. Implemented by *http.Client
+	//
+	//Errors returned by a Doer are transport errors, retried by the RetryPolicy, unless they wrap model.ErrRequest
+	//to refuse the request for good.
+	Doer = internal.Doer
+
+	//DoerFunc adapts an ordinary function to a Doer
+	DoerFunc func(req *http.Request) (*http.Response, error)
+
+	//Middleware wraps a Doer to act on every request and response, e.g. to add headers, log or inject faults
+	Middleware func(next Doer) Doer
+
+	//limitedBody response body failing once more than the limit is read
+	limitedBody struct {
+		io.ReadCloser
+		remaining int64
+		limit     int64
+	}
+)
+
+//Do calls f(req)
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+//WithDoer sets the Doer executing the requests, e.g. an instrumented HTTP client or a test double. The Doer replaces
+//the HTTP client, so WithHTTPClient and WithTimeout have no effect.
+func WithDoer(doer Doer) Option {
+	return func(c *Client) {
+		c.doer = doer
+	}
+}
+
+//WithMiddleware adds middlewares wrapping the Doer of the Client. The first middleware added is the outermost, seeing
+//each request first and each response last.
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(c *Client) {
+		c.middleware = append(c.middleware, middleware...)
+	}
+}
+
+//UserAgent returns a middleware setting the User-Agent header of every request
+func UserAgent(agent string) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			req.Header.Set("User-Agent", agent)
+			return next.Do(req)
+		})
+	}
+}
+
+//RequestID returns a middleware setting a unique ID in the given header of every request that does not carry one,
+//e.g. to correlate requests with gateway logs. The header defaults to X-Request-ID; generate defaults to random
+//128-bit hexadecimal IDs.
+func RequestID(header string, generate func() string) Middleware {
+	if header == "" {
+		header = defaultRequestIDHeader
+	}
+	if generate == nil {
+		generate = randomID
+	}
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get(header) == "" {
+				req = req.Clone(req.Context())
+				req.Header.Set(header, generate())
+			}
+			return next.Do(req)
+		})
+	}
+}
+
+//MaxBodySize returns a middleware failing responses with a body larger than the given number of bytes, protecting
+//the Client from unexpectedly large responses. The size limit is a request error wrapping model.ErrRequest, so the
+//oversized response is not downloaded again by the RetryPolicy.
+func MaxBodySize(limit int64) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := next.Do(req)
+			if err != nil {
+				return nil, err
+			}
+			if resp.ContentLength > limit {
+				resp.Body.Close()
+				return nil, fmt.Errorf("%w: response body of %d bytes exceeds limit of %d bytes", model.ErrRequest, resp.ContentLength, limit)
+			}
+			resp.Body = &limitedBody{ReadCloser: resp.Body, remaining: limit, limit: limit}
+			return resp, nil
+		})
+	}
+}
+
+//chain returns the Doer executing the requests wrapped in the configured middlewares
+func (c *Client) chain() Doer {
+	var doer Doer
+	switch {
+	case c.doer != nil:
+		doer = c.doer
+	case c.httpClient != nil:
+		doer = c.httpClient
+	default:
+		doer = defaultHTTPClient
+	}
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		doer = c.middleware[i](doer)
+	}
+	return doer
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		var probe [1]byte
+		n, err := b.ReadCloser.Read(probe[:])
+		if n > 0 {
+			return 0, fmt.Errorf("%w: response body exceeds limit of %d bytes", model.ErrRequest, b.limit)
+		}
+		return 0, err
+	}
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	return n, err
+}
+
+func randomID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(id)
+}
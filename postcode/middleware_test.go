@@ -0,0 +1,53 @@
+package postcode
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/razorcorp/postcode-sdk-go/model"
+)
+
+func TestMaxBodySizeNotRetried(t *testing.T) {
+	tests := []struct {
+		name    string
+		chunked bool
+	}{
+		{name: "content length"},
+		{name: "chunked", chunked: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls := new(int32)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(calls, 1)
+				w.Header().Set("Content-Type", "application/json")
+				if test.chunked {
+					// flushing before the body is written leaves its length unknown
+					w.(http.Flusher).Flush()
+				}
+				w.Write([]byte(lookupBody))
+			}))
+			t.Cleanup(server.Close)
+
+			client := NewClient(WithBaseURL(server.URL), WithRetryPolicy(testPolicy), WithMiddleware(MaxBodySize(16)))
+			_, err := client.Lookup("RG1 1AF")
+			if !errors.Is(err, model.ErrRequest) || errors.Is(err, model.ErrTransport) {
+				t.Errorf("Lookup() error = %v, want a request error", err)
+			}
+			if n := atomic.LoadInt32(calls); n != 1 {
+				t.Errorf("attempts = %d, want 1", n)
+			}
+		})
+	}
+}
+
+func TestMaxBodySizeWithinLimit(t *testing.T) {
+	server, _ := failingServer(t, 0, nil, lookupBody)
+	client := NewClient(WithBaseURL(server.URL), WithMiddleware(MaxBodySize(int64(len(lookupBody)))))
+	if data, err := client.Lookup("RG1 1AF"); err != nil || data.Postcode != "RG1 1AF" {
+		t.Errorf("Lookup() = %+v, %v, want RG1 1AF", data, err)
+	}
+}
@@ -0,0 +1,437 @@
+//Package onspd provides a postcode.Backend answering from a local copy of the ONS Postcode Directory (ONSPD) or the
+//National Statistics Postcode Lookup (NSPL), for environments without access to postcodes.io
+package onspd
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/razorcorp/postcode-sdk-go/model"
+	"github.com/razorcorp/postcode-sdk-go/postcode"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var _ postcode.Backend = (*Dataset)(nil)
+
+//columns header names of each attribute in the ONSPD and NSPL releases, in order of preference
+var columns = map[string][]string{
+	"postcode":   {"pcds", "pcd2", "pcd"},
+	"introduced": {"dointr"},
+	"terminated": {"doterm"},
+	"county":     {"oscty", "cty"},
+	"ced":        {"ced"},
+	"district":   {"oslaua", "laua", "lad"},
+	"ward":       {"osward", "ward"},
+	"parish":     {"parish"},
+	"eastings":   {"oseast1m"},
+	"northings":  {"osnrth1m"},
+	"quality":    {"osgrdind"},
+	"nhs_ha":     {"oshlthau", "hlthau"},
+	"country":    {"ctry"},
+	"region":     {"rgn"},
+	"pcon":       {"pcon"},
+	"eer":        {"eer"},
+	"pct":        {"pct"},
+	"nuts":       {"itl", "nuts"},
+	"lsoa":       {"lsoa21", "lsoa11"},
+	"msoa":       {"msoa21", "msoa11"},
+	"ccg":        {"sicbl", "ccg"},
+	"latitude":   {"lat"},
+	"longitude":  {"long"},
+}
+
+//names of the countries and the regions of England, by GSS code
+var names = map[string]string{
+	"E92000001": "England",
+	"W92000004": "Wales",
+	"S92000003": "Scotland",
+	"N92000002": "Northern Ireland",
+	"L93000001": "Channel Islands",
+	"M83000003": "Isle of Man",
+	"E12000001": "North East",
+	"E12000002": "North West",
+	"E12000003": "Yorkshire and The Humber",
+	"E12000004": "East Midlands",
+	"E12000005": "West Midlands",
+	"E12000006": "East of England",
+	"E12000007": "London",
+	"E12000008": "South East",
+	"E12000009": "South West",
+}
+
+type (
+	//Options configures the loading of a Dataset
+	Options struct {
+		//Vintage release of the dataset, e.g. "2024-05". Defaults to the latest month a postcode was introduced or
+		//terminated in
+		Vintage string
+
+		//Names of the administrative areas by GSS code, e.g. "E06000038": "Reading", as published in the documents
+		//of each release. The directory only holds the codes, so the names of the areas are left empty unless given,
+		//except for the countries and the regions of England.
+		Names map[string]string
+	}
+
+	//Dataset is a postcode.Backend holding an ONSPD or NSPL release in memory. Live postcodes are answered with their
+	//administrative area codes, coordinates and names; terminated postcodes are answered by TerminatedPostcodeLookup
+	//only, as by the API. Outcodes are derived from their live postcodes. Dataset is safe for concurrent use.
+	Dataset struct {
+		vintage    string
+		postcodes  []record
+		terminated map[string]model.TerminatedPostcode
+		outcodes   map[string]model.OutcodeData
+	}
+
+	//record live postcode keyed by its compact form, e.g. RG122PE
+	record struct {
+		key      string
+		postcode model.Postcode
+	}
+
+	//loader state of the loading of a CSV file
+	loader struct {
+		options  Options
+		index    map[string]int
+		interned map[string]string
+		latest   string
+		counts   map[string]int
+	}
+)
+
+//Open loads the ONSPD or NSPL CSV file at the given path
+func Open(path string, options Options) (*Dataset, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Load(file, options)
+}
+
+//Load reads an ONSPD or NSPL CSV file with its header row. The columns are matched by name, case insensitively, so
+//releases with different sets of columns are supported as long as they have a postcode column.
+func Load(r io.Reader, options Options) (*Dataset, error) {
+	reader := csv.NewReader(bufio.NewReaderSize(r, 1<<20))
+	reader.ReuseRecord = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("onspd: reading header: %w", err)
+	}
+	l := &loader{
+		options:  options,
+		index:    make(map[string]int),
+		interned: make(map[string]string),
+		counts:   make(map[string]int),
+	}
+	for attribute, aliases := range columns {
+		l.index[attribute] = -1
+		for _, alias := range aliases {
+			if i := indexOf(header, alias); i >= 0 {
+				l.index[attribute] = i
+				break
+			}
+		}
+	}
+	if l.index["postcode"] < 0 {
+		return nil, errors.New("onspd: no postcode column in header")
+	}
+
+	d := &Dataset{
+		terminated: make(map[string]model.TerminatedPostcode),
+		outcodes:   make(map[string]model.OutcodeData),
+	}
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("onspd: line %d: %w", line, err)
+		}
+		l.add(d, row)
+	}
+
+	sort.Slice(d.postcodes, func(i, j int) bool {
+		return d.postcodes[i].key < d.postcodes[j].key
+	})
+	for code, outcode := range d.outcodes {
+		n := float64(l.counts[code])
+		if n > 0 {
+			outcode.Latitude /= n
+			outcode.Longitude /= n
+			outcode.Eastings = int64(float64(outcode.Eastings) / n)
+			outcode.Northings = int64(float64(outcode.Northings) / n)
+		}
+		d.outcodes[code] = outcode
+	}
+
+	d.vintage = options.Vintage
+	if d.vintage == "" && len(l.latest) == 6 {
+		d.vintage = l.latest[:4] + "-" + l.latest[4:]
+	}
+	return d, nil
+}
+
+//Vintage returns the release of the dataset, e.g. "2024-05"
+func (d *Dataset) Vintage() string {
+	return d.vintage
+}
+
+//Len returns the number of live postcodes of the dataset
+func (d *Dataset) Len() int {
+	return len(d.postcodes)
+}
+
+func (d *Dataset) Lookup(_ context.Context, code string) (*model.Postcode, *model.ResponseError) {
+	data, ok := d.find(code)
+	if !ok {
+		return nil, notFound("Postcode not found")
+	}
+	return &data, nil
+}
+
+func (d *Dataset) BulkLookup(_ context.Context, codes []string) ([]model.Postcodes, *model.ResponseError) {
+	results := make([]model.Postcodes, len(codes))
+	for i, code := range codes {
+		results[i].Query = code
+		results[i].Postcode, _ = d.find(code)
+	}
+	return results, nil
+}
+
+func (d *Dataset) Validation(_ context.Context, code string) (bool, *model.ResponseError) {
+	_, ok := d.find(code)
+	return ok, nil
+}
+
+func (d *Dataset) Autocomplete(_ context.Context, code string, limit int) ([]string, *model.ResponseError) {
+	var data []string
+	for _, r := range d.prefixed(code, limit) {
+		data = append(data, r.postcode.Postcode)
+	}
+	return data, nil
+}
+
+func (d *Dataset) Query(_ context.Context, query string, limit int) ([]model.Postcode, *model.ResponseError) {
+	var data []model.Postcode
+	for _, r := range d.prefixed(query, limit) {
+		data = append(data, r.postcode)
+	}
+	return data, nil
+}
+
+func (d *Dataset) OutcodeLookup(_ context.Context, outcode string) (*model.OutcodeData, *model.ResponseError) {
+	data, ok := d.outcodes[compact(outcode)]
+	if !ok {
+		return nil, notFound("Outcode not found")
+	}
+	return &data, nil
+}
+
+func (d *Dataset) TerminatedPostcodeLookup(_ context.Context, code string) (*model.TerminatedPostcode, *model.ResponseError) {
+	data, ok := d.terminated[compact(code)]
+	if !ok {
+		return nil, notFound("Terminated postcode not found")
+	}
+	return &data, nil
+}
+
+//find returns the live postcode
+func (d *Dataset) find(code string) (model.Postcode, bool) {
+	key := compact(code)
+	i := sort.Search(len(d.postcodes), func(i int) bool {
+		return d.postcodes[i].key >= key
+	})
+	if i < len(d.postcodes) && d.postcodes[i].key == key {
+		return d.postcodes[i].postcode, true
+	}
+	return model.Postcode{}, false
+}
+
+//prefixed returns up to limit live postcodes starting with the given prefix, in order
+func (d *Dataset) prefixed(prefix string, limit int) []record {
+	key := compact(prefix)
+	if key == "" {
+		return nil
+	}
+	i := sort.Search(len(d.postcodes), func(i int) bool {
+		return d.postcodes[i].key >= key
+	})
+	j := i
+	for j < len(d.postcodes) && j-i < limit && strings.HasPrefix(d.postcodes[j].key, key) {
+		j++
+	}
+	return d.postcodes[i:j]
+}
+
+//add adds the given CSV row to the dataset
+func (l *loader) add(d *Dataset, row []string) {
+	code := l.field(row, "postcode")
+	if code == "" {
+		return
+	}
+	if parts, err := postcode.Parse(code); err == nil {
+		code = parts.String()
+	} else {
+		code = strings.ToUpper(strings.Join(strings.Fields(code), " "))
+	}
+	key := compact(code)
+
+	latitude, longitude, hasCoordinates := l.coordinates(row)
+	introduced, terminated := l.field(row, "introduced"), l.field(row, "terminated")
+	for _, month := range []string{introduced, terminated} {
+		if len(month) == 6 && month > l.latest {
+			l.latest = month
+		}
+	}
+
+	if terminated != "" {
+		data := model.TerminatedPostcode{Postcode: code, Latitude: latitude, Longitude: longitude}
+		if len(terminated) == 6 {
+			data.YearTerminated, _ = strconv.ParseInt(terminated[:4], 10, 64)
+			data.MonthTerminated, _ = strconv.ParseInt(terminated[4:], 10, 64)
+		}
+		d.terminated[key] = data
+		return
+	}
+
+	data := model.Postcode{
+		Postcode:  code,
+		Latitude:  latitude,
+		Longitude: longitude,
+		Codes: model.Codes{
+			AdminDistrict:             l.field(row, "district"),
+			AdminCounty:               l.field(row, "county"),
+			AdminWard:                 l.field(row, "ward"),
+			Parish:                    l.field(row, "parish"),
+			ParliamentaryConstituency: l.field(row, "pcon"),
+			Ccg:                       l.field(row, "ccg"),
+			Ced:                       l.field(row, "ced"),
+			Nuts:                      l.field(row, "nuts"),
+			Lsoa:                      l.field(row, "lsoa"),
+			Msoa:                      l.field(row, "msoa"),
+		},
+	}
+	if i := strings.LastIndex(code, " "); i > 0 {
+		data.OutCode, data.InCode = code[:i], code[i+1:]
+	}
+	data.Eastings, _ = strconv.ParseInt(l.field(row, "eastings"), 10, 64)
+	data.Northings, _ = strconv.ParseInt(l.field(row, "northings"), 10, 64)
+	data.Quality, _ = strconv.ParseInt(l.field(row, "quality"), 10, 64)
+	data.Country = l.name(l.field(row, "country"))
+	data.Region = l.name(l.field(row, "region"))
+	data.NhsHa = l.name(l.field(row, "nhs_ha"))
+	data.PrimaryCareTrust = l.name(l.field(row, "pct"))
+	data.EuropeanElectoralRegion = l.name(l.field(row, "eer"))
+	data.AdminDistrict = l.name(data.Codes.AdminDistrict)
+	data.AdminCounty = l.name(data.Codes.AdminCounty)
+	data.AdminWard = l.name(data.Codes.AdminWard)
+	data.Parish = l.name(data.Codes.Parish)
+	data.ParliamentaryConstituency = l.name(data.Codes.ParliamentaryConstituency)
+	data.Ccg = l.name(data.Codes.Ccg)
+	data.Ced = l.name(data.Codes.Ced)
+	data.Nuts = l.name(data.Codes.Nuts)
+	data.Lsoa = l.name(data.Codes.Lsoa)
+	data.Msoa = l.name(data.Codes.Msoa)
+	d.postcodes = append(d.postcodes, record{key: key, postcode: data})
+
+	if data.OutCode == "" {
+		return
+	}
+	outcode := d.outcodes[data.OutCode]
+	outcode.Outcode = data.OutCode
+	if hasCoordinates {
+		outcode.Latitude += latitude
+		outcode.Longitude += longitude
+		outcode.Eastings += data.Eastings
+		outcode.Northings += data.Northings
+		l.counts[data.OutCode]++
+	}
+	outcode.AdminDistrict = appendDistinct(outcode.AdminDistrict, data.AdminDistrict)
+	outcode.AdminCounty = appendDistinct(outcode.AdminCounty, data.AdminCounty)
+	outcode.AdminWard = appendDistinct(outcode.AdminWard, data.AdminWard)
+	outcode.Parish = appendDistinct(outcode.Parish, data.Parish)
+	outcode.Country = appendDistinct(outcode.Country, data.Country)
+	d.outcodes[data.OutCode] = outcode
+}
+
+//field returns the value of the given attribute in the row, interned as the values repeat across millions of rows
+func (l *loader) field(row []string, attribute string) string {
+	i := l.index[attribute]
+	if i < 0 || i >= len(row) {
+		return ""
+	}
+	value := strings.TrimSpace(row[i])
+	if value == "" {
+		return ""
+	}
+	interned, ok := l.interned[value]
+	if !ok {
+		interned = strings.Clone(value)
+		l.interned[interned] = interned
+	}
+	return interned
+}
+
+//name returns the name of the area of the given GSS code, or an empty string if unknown
+func (l *loader) name(code string) string {
+	if name, ok := l.options.Names[code]; ok {
+		return name
+	}
+	return names[code]
+}
+
+//coordinates returns the latitude and longitude of the row. Postcodes without a grid reference are given 99.999999
+//and 0.000000 in the directory, returned as zero.
+func (l *loader) coordinates(row []string) (float64, float64, bool) {
+	latitude, latErr := strconv.ParseFloat(l.field(row, "latitude"), 64)
+	longitude, lonErr := strconv.ParseFloat(l.field(row, "longitude"), 64)
+	if latErr != nil || lonErr != nil || latitude > 90 || latitude < -90 {
+		return 0, 0, false
+	}
+	return latitude, longitude, true
+}
+
+func notFound(message string) *model.ResponseError {
+	return &model.ResponseError{
+		Status:  http.StatusNotFound,
+		Message: message,
+	}
+}
+
+//indexOf returns the index of the given column in the header, ignoring case and a byte order mark, or -1
+func indexOf(header []string, column string) int {
+	for i, name := range header {
+		if strings.EqualFold(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")), column) {
+			return i
+		}
+	}
+	return -1
+}
+
+//compact returns the given postcode in upper case without white space
+func compact(code string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(code), ""))
+}
+
+//appendDistinct appends the value to the list unless empty or already present
+func appendDistinct(list []string, value string) []string {
+	if value == "" {
+		return list
+	}
+	for _, item := range list {
+		if item == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
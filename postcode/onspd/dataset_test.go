@@ -0,0 +1,220 @@
+package onspd
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/razorcorp/postcode-sdk-go/model"
+)
+
+func openSample(t *testing.T, options Options) *Dataset {
+	t.Helper()
+	dataset, err := Open("testdata/ONSPD_sample.csv", options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dataset
+}
+
+func TestOpen(t *testing.T) {
+	dataset := openSample(t, Options{Names: map[string]string{"E06000038": "Reading", "E05013864": "Abbey"}})
+	if dataset.Len() != 7 {
+		t.Errorf("Len() = %d, want 7 live postcodes", dataset.Len())
+	}
+
+	data, err := dataset.Lookup(context.Background(), "rg11af")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := model.Postcode{
+		Postcode:      data.Postcode,
+		OutCode:       "RG1",
+		InCode:        "1AF",
+		Quality:       1,
+		Eastings:      471435,
+		Northings:     173331,
+		Country:       "England",
+		Region:        "South East",
+		AdminDistrict: "Reading",
+		AdminWard:     "Abbey",
+		Latitude:      51.456813,
+		Longitude:     -0.971396,
+		Codes:         data.Codes,
+	}
+	if data.Postcode != "RG1 1AF" || *data != want {
+		t.Errorf("Lookup() = %+v, want %+v", data, want)
+	}
+	if data.Codes.AdminDistrict != "E06000038" || data.Codes.Nuts != "TLJ11" || data.Codes.Lsoa != "E01016406" || data.Codes.Ccg != "E38000146" {
+		t.Errorf("Lookup() codes = %+v", data.Codes)
+	}
+
+	if _, err := dataset.Lookup(context.Background(), "RG1 9ZZ"); err == nil || !errors.Is(err, model.ErrNotFound) || err.Message != "Postcode not found" {
+		t.Errorf("Lookup() of an unknown postcode error = %v, want Postcode not found", err)
+	}
+}
+
+func TestTerminated(t *testing.T) {
+	dataset := openSample(t, Options{})
+
+	if _, err := dataset.Lookup(context.Background(), "RG1 1AB"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("Lookup() of a terminated postcode error = %v, want not found", err)
+	}
+	if valid, _ := dataset.Validation(context.Background(), "RG1 1AB"); valid {
+		t.Error("Validation() of a terminated postcode = true")
+	}
+
+	data, err := dataset.TerminatedPostcodeLookup(context.Background(), "rg1 1ab")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := model.TerminatedPostcode{Postcode: "RG1 1AB", YearTerminated: 1999, MonthTerminated: 6, Latitude: 51.456288, Longitude: -0.970862}
+	if *data != want {
+		t.Errorf("TerminatedPostcodeLookup() = %+v, want %+v", *data, want)
+	}
+	if _, err := dataset.TerminatedPostcodeLookup(context.Background(), "RG1 1AF"); err == nil || err.Message != "Terminated postcode not found" {
+		t.Errorf("TerminatedPostcodeLookup() of a live postcode error = %v, want Terminated postcode not found", err)
+	}
+}
+
+func TestNoCoordinates(t *testing.T) {
+	dataset := openSample(t, Options{})
+
+	data, err := dataset.Lookup(context.Background(), "ZE3 9JZ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.Latitude != 0 || data.Longitude != 0 || data.Eastings != 0 || data.Northings != 0 || data.Quality != 9 {
+		t.Errorf("Lookup() = %+v, want no coordinates and quality 9", data)
+	}
+
+	outcode, err := dataset.OutcodeLookup(context.Background(), "ZE3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outcode.Latitude != 0 || outcode.Longitude != 0 || len(outcode.Country) != 1 || outcode.Country[0] != "Scotland" {
+		t.Errorf("OutcodeLookup() = %+v, want Scotland without coordinates", outcode)
+	}
+}
+
+func TestOutcodeLookup(t *testing.T) {
+	dataset := openSample(t, Options{Names: map[string]string{"E06000038": "Reading"}})
+
+	data, err := dataset.OutcodeLookup(context.Background(), "rg1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// RG1 1AF, RG1 1AZ and RG1 2AG; the terminated RG1 1AB is left out
+	latitude := (51.456813 + 51.455915 + 51.454196) / 3
+	if data.Outcode != "RG1" || data.Latitude-latitude > 1e-9 || latitude-data.Latitude > 1e-9 || data.Eastings != (471435+471558+471217)/3 {
+		t.Errorf("OutcodeLookup() = %+v, want the centre of the live postcodes of RG1", data)
+	}
+	if len(data.AdminDistrict) != 1 || data.AdminDistrict[0] != "Reading" {
+		t.Errorf("OutcodeLookup() districts = %v, want Reading", data.AdminDistrict)
+	}
+	if _, err := dataset.OutcodeLookup(context.Background(), "RG99"); err == nil || err.Message != "Outcode not found" {
+		t.Errorf("OutcodeLookup() of an unknown outcode error = %v, want Outcode not found", err)
+	}
+}
+
+func TestVintage(t *testing.T) {
+	if vintage := openSample(t, Options{}).Vintage(); vintage != "2005-12" {
+		t.Errorf("Vintage() = %q, want the latest month of the sample, 2005-12", vintage)
+	}
+	if vintage := openSample(t, Options{Vintage: "2024-05"}).Vintage(); vintage != "2024-05" {
+		t.Errorf("Vintage() = %q, want 2024-05 as given", vintage)
+	}
+
+	dataset, err := Load(strings.NewReader("pcds,doterm\nRG1 1AB,201302\nRG1 1AF,\n"), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vintage := dataset.Vintage(); vintage != "2013-02" {
+		t.Errorf("Vintage() = %q, want the latest termination, 2013-02", vintage)
+	}
+	dataset, err = Load(strings.NewReader("pcds\nRG1 1AF\n"), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vintage := dataset.Vintage(); vintage != "" {
+		t.Errorf("Vintage() = %q, want none without dates", vintage)
+	}
+}
+
+func TestColumnAliases(t *testing.T) {
+	// NSPL style header with a byte order mark, upper case names and the 2011 census areas
+	csv := "\ufeffPCD,LAD,LAUA,WARD,NUTS,LSOA11,MSOA11,CCG,CTRY,LAT,LONG\n" +
+		"RG122PE,E99999999,E06000036,E05014542,UKJ11,E01016158,E02003352,E38000146,E92000001,51.411893,-0.751853\n"
+	dataset, err := Load(strings.NewReader(csv), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, lookupErr := dataset.Lookup(context.Background(), "RG12 2PE")
+	if lookupErr != nil {
+		t.Fatal(lookupErr)
+	}
+	want := model.Codes{AdminDistrict: "E06000036", AdminWard: "E05014542", Nuts: "UKJ11", Lsoa: "E01016158", Msoa: "E02003352", Ccg: "E38000146"}
+	if data.Postcode != "RG12 2PE" || data.OutCode != "RG12" || data.Codes != want {
+		t.Errorf("Lookup() = %s %s %+v, want RG12 2PE with codes %+v", data.Postcode, data.OutCode, data.Codes, want)
+	}
+	if data.Country != "England" || data.Latitude != 51.411893 || data.Longitude != -0.751853 {
+		t.Errorf("Lookup() = %+v, want England at 51.411893,-0.751853", data)
+	}
+
+	if _, err := Load(strings.NewReader("lat,long\n51.4,-0.7\n"), Options{}); err == nil {
+		t.Error("Load() without a postcode column error = nil")
+	}
+}
+
+func TestPrefixes(t *testing.T) {
+	dataset := openSample(t, Options{})
+	ctx := context.Background()
+
+	tests := []struct {
+		prefix string
+		limit  int
+		want   []string
+	}{
+		{prefix: "RG1", limit: 10, want: []string{"RG1 1AF", "RG1 1AZ", "RG12 2PE", "RG1 2AG"}},
+		{prefix: "rg1 1", limit: 10, want: []string{"RG1 1AF", "RG1 1AZ"}},
+		{prefix: "RG1", limit: 2, want: []string{"RG1 1AF", "RG1 1AZ"}},
+		{prefix: "SW1A1", limit: 10, want: []string{"SW1A 1AA"}},
+		{prefix: "RG1 1AB", limit: 10, want: nil},
+		{prefix: "AB1", limit: 10, want: nil},
+		{prefix: " ", limit: 10, want: nil},
+	}
+	for _, test := range tests {
+		completed, err := dataset.Autocomplete(ctx, test.prefix, test.limit)
+		if err != nil || strings.Join(completed, "|") != strings.Join(test.want, "|") {
+			t.Errorf("Autocomplete(%q, %d) = %v, %v, want %v", test.prefix, test.limit, completed, err, test.want)
+		}
+
+		found, err := dataset.Query(ctx, test.prefix, test.limit)
+		postcodes := make([]string, len(found))
+		for i := range found {
+			postcodes[i] = found[i].Postcode
+		}
+		if err != nil || strings.Join(postcodes, "|") != strings.Join(test.want, "|") {
+			t.Errorf("Query(%q, %d) = %v, %v, want %v", test.prefix, test.limit, postcodes, err, test.want)
+		}
+	}
+}
+
+func TestBulkLookup(t *testing.T) {
+	dataset := openSample(t, Options{})
+
+	data, err := dataset.BulkLookup(context.Background(), []string{"SW1A 1AA", "RG1 9ZZ", "RG1 1AB", "eh11yz"})
+	if err != nil || len(data) != 4 {
+		t.Fatalf("BulkLookup() = %v, %v", data, err)
+	}
+	for i, want := range []string{"SW1A 1AA", "", "", "EH1 1YZ"} {
+		if data[i].Postcode.Postcode != want {
+			t.Errorf("BulkLookup() result %d = %q, want %q", i, data[i].Postcode.Postcode, want)
+		}
+	}
+	if data[1].Query != "RG1 9ZZ" || data[3].Query != "eh11yz" {
+		t.Errorf("BulkLookup() queries = %q and %q, want them as given", data[1].Query, data[3].Query)
+	}
+}
@@ -0,0 +1,118 @@
+package postcode
+
+import (
+	"github.com/razorcorp/postcode-sdk-go/model"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+type (
+	//PostcodeParts is a postcode broken down into its Royal Mail components, e.g. for "RG12 2PE"
+	//
+	//	Area: RG, District: RG12, Sector: RG12 2, Outcode: RG12, Incode: 2PE, Unit: PE
+	//
+	//British Forces (BFPO) postcodes have no sector and unit.
+	PostcodeParts struct {
+		Area     string
+		District string
+		Sector   string
+		Outcode  string
+		Incode   string
+		Unit     string
+	}
+)
+
+//bfpoPattern British Forces Post Office numbers
+var bfpoPattern = regexp.MustCompile(`^BFPO([0-9]{1,4})$`)
+
+//Parse Validates the given postcode against the Royal Mail format rules and breaks it down into its components.
+//Case and white space are ignored.
+//
+//Besides the standard formats Parse accepts GIR 0AA, BFPO numbers and the overseas territories postcodes such as
+//ASCN 1ZZ. Returns an error matching model.ErrInvalidPostcode if the postcode is malformed.
+func Parse(postcode string) (PostcodeParts, error) {
+	parts, err := parse(postcode)
+	if err != nil {
+		return PostcodeParts{}, err
+	}
+	return parts, nil
+}
+
+//ParseOutcode Validates the given outward code against the Royal Mail format rules and returns it in its canonical
+//form, e.g. "rg12" as "RG12". Returns an error matching model.ErrInvalidPostcode if the outward code is malformed.
+func ParseOutcode(outcode string) (string, error) {
+	canonical, err := parseOutcode(outcode)
+	if err != nil {
+		return "", err
+	}
+	return canonical, nil
+}
+
+//String returns the canonical form of the postcode, e.g. "RG12 2PE"
+func (p PostcodeParts) String() string {
+	if p.Outcode == "" {
+		return ""
+	}
+	return p.Outcode + " " + p.Incode
+}
+
+//parse validates the postcode against the Royal Mail format rules of model.ParseUnit, plus the BFPO numbers
+func parse(postcode string) (PostcodeParts, *model.ResponseError) {
+	compact := compact(postcode)
+
+	if match := bfpoPattern.FindStringSubmatch(compact); match != nil {
+		return PostcodeParts{Area: "BFPO", District: "BFPO", Outcode: "BFPO", Incode: match[1]}, nil
+	}
+
+	unit, err := model.ParseUnit(compact)
+	if err != nil {
+		return PostcodeParts{}, invalidPostcode("Invalid postcode")
+	}
+	outcode := string(unit.District())
+	incode := string(unit[len(outcode)+1:])
+	return PostcodeParts{
+		Area:     string(unit.Area()),
+		District: outcode,
+		Sector:   string(unit.Sector()),
+		Outcode:  outcode,
+		Incode:   incode,
+		Unit:     incode[1:],
+	}, nil
+}
+
+func parseOutcode(outcode string) (string, *model.ResponseError) {
+	district, err := model.ParseDistrict(outcode)
+	if err != nil {
+		return "", invalidPostcode("Invalid outcode")
+	}
+	return string(district), nil
+}
+
+//normalise returns the canonical form of the given postcode, e.g. "RG12 2PE" for "rg122pe", or the postcode in upper
+//case without white space if it is malformed
+func normalise(postcode string) string {
+	if parts, err := parse(postcode); err == nil {
+		return parts.String()
+	}
+	return compact(postcode)
+}
+
+//valid reports whether the given postcode is well-formed
+func valid(postcode string) bool {
+	_, err := parse(postcode)
+	return err == nil
+}
+
+//compact returns the given postcode in upper case without white space
+func compact(postcode string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(postcode), ""))
+}
+
+func invalidPostcode(message string) *model.ResponseError {
+	return &model.ResponseError{
+		Status:  http.StatusNotFound,
+		Message: message,
+		Kind:    model.ErrInvalidPostcode,
+	}
+}
@@ -0,0 +1,131 @@
+package postcode
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/razorcorp/postcode-sdk-go/model"
+)
+
+func TestParseValid(t *testing.T) {
+	tests := []struct {
+		name     string
+		postcode string
+		want     PostcodeParts
+	}{
+		{name: "A9", postcode: "M1 1AE", want: PostcodeParts{Area: "M", District: "M1", Sector: "M1 1", Outcode: "M1", Incode: "1AE", Unit: "AE"}},
+		{name: "A99", postcode: "B33 8TH", want: PostcodeParts{Area: "B", District: "B33", Sector: "B33 8", Outcode: "B33", Incode: "8TH", Unit: "TH"}},
+		{name: "A9A", postcode: "W1A 0AX", want: PostcodeParts{Area: "W", District: "W1A", Sector: "W1A 0", Outcode: "W1A", Incode: "0AX", Unit: "AX"}},
+		{name: "AA9", postcode: "CR2 6XH", want: PostcodeParts{Area: "CR", District: "CR2", Sector: "CR2 6", Outcode: "CR2", Incode: "6XH", Unit: "XH"}},
+		{name: "AA99", postcode: "DN55 1PT", want: PostcodeParts{Area: "DN", District: "DN55", Sector: "DN55 1", Outcode: "DN55", Incode: "1PT", Unit: "PT"}},
+		{name: "AA9A", postcode: "EC1A 1BB", want: PostcodeParts{Area: "EC", District: "EC1A", Sector: "EC1A 1", Outcode: "EC1A", Incode: "1BB", Unit: "BB"}},
+		{name: "case and white space", postcode: " rg1 22pe ", want: PostcodeParts{Area: "RG", District: "RG12", Sector: "RG12 2", Outcode: "RG12", Incode: "2PE", Unit: "PE"}},
+		{name: "without space", postcode: "sw1a1aa", want: PostcodeParts{Area: "SW", District: "SW1A", Sector: "SW1A 1", Outcode: "SW1A", Incode: "1AA", Unit: "AA"}},
+		{name: "Girobank", postcode: "GIR 0AA", want: PostcodeParts{Area: "GIR", District: "GIR", Sector: "GIR 0", Outcode: "GIR", Incode: "0AA", Unit: "AA"}},
+		{name: "Girobank without space", postcode: "gir0aa", want: PostcodeParts{Area: "GIR", District: "GIR", Sector: "GIR 0", Outcode: "GIR", Incode: "0AA", Unit: "AA"}},
+		{name: "BFPO", postcode: "BFPO 1234", want: PostcodeParts{Area: "BFPO", District: "BFPO", Outcode: "BFPO", Incode: "1234"}},
+		{name: "BFPO single digit", postcode: "bfpo 7", want: PostcodeParts{Area: "BFPO", District: "BFPO", Outcode: "BFPO", Incode: "7"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Parse(test.postcode)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", test.postcode, err)
+			}
+			if got != test.want {
+				t.Errorf("Parse(%q) = %+v, want %+v", test.postcode, got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseOverseasTerritories(t *testing.T) {
+	for _, postcode := range []string{"ASCN 1ZZ", "BBND 1ZZ", "BIQQ 1ZZ", "FIQQ 1ZZ", "PCRN 1ZZ", "SIQQ 1ZZ", "STHL 1ZZ", "TDCU 1ZZ", "TKCA 1ZZ"} {
+		got, err := Parse(postcode)
+		if err != nil {
+			t.Errorf("Parse(%q) error = %v", postcode, err)
+			continue
+		}
+		if got.String() != postcode || got.Area != postcode[:4] {
+			t.Errorf("Parse(%q) = %+v", postcode, got)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		postcode string
+	}{
+		{name: "empty", postcode: ""},
+		{name: "outcode only", postcode: "RG12"},
+		{name: "three letter area", postcode: "ABC1 1AA"},
+		{name: "three digit district", postcode: "A123 1AA"},
+		{name: "digit area", postcode: "11 1AA"},
+		{name: "letter sector", postcode: "M1 AAA"},
+		{name: "digit unit", postcode: "M1 11A"},
+		{name: "Q first", postcode: "Q1 1AA"},
+		{name: "V first", postcode: "V1 1AA"},
+		{name: "X first", postcode: "X1 1AA"},
+		{name: "I second", postcode: "AI1 1AA"},
+		{name: "J second", postcode: "AJ1 1AA"},
+		{name: "Z second", postcode: "AZ1 1AA"},
+		{name: "I third of A9A", postcode: "W1I 1AA"},
+		{name: "L third of A9A", postcode: "W1L 1AA"},
+		{name: "C fourth of AA9A", postcode: "EC1C 1BB"},
+		{name: "D fourth of AA9A", postcode: "EC1D 1BB"},
+		{name: "C in unit", postcode: "M1 1CA"},
+		{name: "I in unit", postcode: "M1 1AI"},
+		{name: "K in unit", postcode: "M1 1KA"},
+		{name: "M in unit", postcode: "M1 1AM"},
+		{name: "O in unit", postcode: "M1 1OA"},
+		{name: "V in unit", postcode: "M1 1AV"},
+		{name: "Girobank other incode", postcode: "GIR 1AA"},
+		{name: "overseas territory other incode", postcode: "ASCN 1AA"},
+		{name: "BFPO without number", postcode: "BFPO"},
+		{name: "BFPO five digits", postcode: "BFPO 12345"},
+		{name: "punctuation", postcode: "RG12-2PE"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Parse(test.postcode)
+			if !errors.Is(err, model.ErrInvalidPostcode) {
+				t.Errorf("Parse(%q) = %+v, %v, want %v", test.postcode, got, err, model.ErrInvalidPostcode)
+			}
+		})
+	}
+}
+
+func TestParseOutcode(t *testing.T) {
+	tests := []struct {
+		outcode string
+		want    string
+	}{
+		{outcode: "m1", want: "M1"},
+		{outcode: "B33", want: "B33"},
+		{outcode: "w1a", want: "W1A"},
+		{outcode: "CR2", want: "CR2"},
+		{outcode: " rg12 ", want: "RG12"},
+		{outcode: "EC1A", want: "EC1A"},
+		{outcode: "GIR", want: "GIR"},
+		{outcode: "bfpo", want: "BFPO"},
+		{outcode: "STHL", want: "STHL"},
+		{outcode: "Q1"},
+		{outcode: "AI1"},
+		{outcode: "EC1C"},
+		{outcode: "RG12 2PE"},
+		{outcode: ""},
+	}
+	for _, test := range tests {
+		got, err := ParseOutcode(test.outcode)
+		if test.want == "" {
+			if !errors.Is(err, model.ErrInvalidPostcode) {
+				t.Errorf("ParseOutcode(%q) = %q, %v, want %v", test.outcode, got, err, model.ErrInvalidPostcode)
+			}
+			continue
+		}
+		if err != nil || got != test.want {
+			t.Errorf("ParseOutcode(%q) = %q, %v, want %q", test.outcode, got, err, test.want)
+		}
+	}
+}
@@ -1,13 +1,12 @@
 package postcode
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"github.com/razorcorp/postcode-sdk-go/model"
 	"github.com/razorcorp/postcode-sdk-go/postcode/internal"
-	"net/http"
+	"io"
 	"strconv"
-	"strings"
 )
 
 /**
@@ -35,22 +34,12 @@ type (
 //
 //Returns a single postcode entity for a given postcode (case, space insensitive).
 func Lookup(postcode string) (*model.Postcode, *model.ResponseError) {
-	client := internal.Client()
-	if err := client.Request(http.MethodGet, fmt.Sprintf("postcodes/%s", postcode), nil); err != nil {
-		return nil, internal.RequestBuildError(err)
-	}
-
-	response, responseError := client.Do()
-	if responseError != nil {
-		return nil, responseError
-	}
-
-	data := new(model.Postcode)
-	if decodeErr := internal.ResponseDecoder(response.Body, data); decodeErr != nil {
-		return nil, decodeErr
-	}
+	return DefaultClient.Lookup(postcode)
+}
 
-	return data, nil
+//LookupContext is like Lookup but carries the given context through to the request
+func LookupContext(ctx context.Context, postcode string) (*model.Postcode, *model.ResponseError) {
+	return DefaultClient.LookupContext(ctx, postcode)
 }
 
 //BulkLookup Accepts an array of postcodes. Returns a list of matching
@@ -64,38 +53,12 @@ func Lookup(postcode string) (*model.Postcode, *model.ResponseError) {
 //
 //Accepts up to 100 postcodes
 func BulkLookup(postcodes Postcodes, filters []string) ([]model.Postcodes, *model.ResponseError) {
-	client := internal.Client()
-	if err := postcodes.validate(); err != nil {
-		return nil, err
-	}
-
-	payload, payloadErr := postcodes.json()
-	if payloadErr != nil {
-		return nil, internal.PayloadEncodeError(payloadErr)
-	}
-
-	if len(filters) > 0 {
-		client.Query = append(client.Query, internal.Query{
-			Key:   "filter",
-			Value: strings.Join(filters, ","),
-		})
-	}
-
-	if err := client.Request(http.MethodPost, "postcodes", payload); err != nil {
-		return nil, internal.RequestBuildError(err)
-	}
-
-	response, responseError := client.Do()
-	if responseError != nil {
-		return nil, responseError
-	}
-
-	var data []model.Postcodes
-	if decodeErr := internal.ResponseDecoder(response.Body, &data); decodeErr != nil {
-		return nil, decodeErr
-	}
+	return DefaultClient.BulkLookup(postcodes, filters)
+}
 
-	return data, nil
+//BulkLookupContext is like BulkLookup but carries the given context through to the request
+func BulkLookupContext(ctx context.Context, postcodes Postcodes, filters []string) ([]model.Postcodes, *model.ResponseError) {
+	return DefaultClient.BulkLookupContext(ctx, postcodes, filters)
 }
 
 //ReverseGeocoding Returns the nearest postcodes for a given longitude and latitude.
@@ -112,53 +75,12 @@ func BulkLookup(postcodes Postcodes, filters []string) ([]model.Postcodes, *mode
 //the trade off between search radius and number of results. Defaults to false.
 //When enabled, radius and limits over 10 are ignored.
 func ReverseGeocoding(geocode Geocode) ([]model.Postcode, *model.ResponseError) {
-	if err := geocode.validate(); err != nil {
-		return nil, err
-	}
-	client := internal.Client()
-	client.Query = append(client.Query, internal.Query{
-		Key:   "lon",
-		Value: strconv.FormatFloat(geocode.Longitude, 'f', 20, 64),
-	})
-	client.Query = append(client.Query, internal.Query{
-		Key:   "lat",
-		Value: strconv.FormatFloat(geocode.Latitude, 'f', 20, 64),
-	})
-
-	if geocode.Limit > 0 {
-		client.Query = append(client.Query, internal.Query{
-			Key:   "limit",
-			Value: strconv.FormatInt(geocode.Limit, 10),
-		})
-	}
-	if geocode.Radius > 0 {
-		client.Query = append(client.Query, internal.Query{
-			Key:   "radius",
-			Value: strconv.FormatInt(geocode.Radius, 10),
-		})
-	}
-	if geocode.WideSearch {
-		client.Query = append(client.Query, internal.Query{
-			Key:   "widesearch",
-			Value: strconv.FormatBool(geocode.WideSearch),
-		})
-	}
-
-	if err := client.Request(http.MethodGet, "postcodes", nil); err != nil {
-		return nil, internal.RequestBuildError(err)
-	}
-
-	response, responseError := client.Do()
-	if responseError != nil {
-		return nil, responseError
-	}
-
-	data := new([]model.Postcode)
-	if decodeErr := internal.ResponseDecoder(response.Body, data); decodeErr != nil {
-		return nil, decodeErr
-	}
+	return DefaultClient.ReverseGeocoding(geocode)
+}
 
-	return *data, nil
+//ReverseGeocodingContext is like ReverseGeocoding but carries the given context through to the request
+func ReverseGeocodingContext(ctx context.Context, geocode Geocode) ([]model.Postcode, *model.ResponseError) {
+	return DefaultClient.ReverseGeocodingContext(ctx, geocode)
 }
 
 //BulkReverseGeocoding Bulk translates geolocations into Postcodes. Accepts up to 100 geolocations.
@@ -175,39 +97,68 @@ func ReverseGeocoding(geocode Geocode) ([]model.Postcode, *model.ResponseError)
 //the trade off between search radius and number of results. Defaults to false.
 //When enabled, radius and limits over 10 are ignored.
 func BulkReverseGeocoding(geocodes Geocodes, filters []string) ([]model.Geocodes, *model.ResponseError) {
-	client := internal.Client()
+	return DefaultClient.BulkReverseGeocoding(geocodes, filters)
+}
 
-	if err := geocodes.validate(); err != nil {
-		return nil, err
-	}
+//BulkReverseGeocodingContext is like BulkReverseGeocoding but carries the given context through to the request
+func BulkReverseGeocodingContext(ctx context.Context, geocodes Geocodes, filters []string) ([]model.Geocodes, *model.ResponseError) {
+	return DefaultClient.BulkReverseGeocodingContext(ctx, geocodes, filters)
+}
 
-	payload, payloadErr := geocodes.json()
-	if payloadErr != nil {
-		return nil, internal.PayloadEncodeError(payloadErr)
-	}
+//BulkReverseGeocodingAll Reverse geocodes any number of geolocations by splitting them into bulk reverse geocoding
+//requests of up to 100 geolocations.
+//
+//Returns a result for each given geolocation in the same order. Invalid geolocations and geolocations belonging to a
+//failed request carry the error of their result instead of failing the whole run.
+func BulkReverseGeocodingAll(geocodes []Geocode, filters []string) []GeocodeResult {
+	return DefaultClient.BulkReverseGeocodingAll(geocodes, filters)
+}
 
-	if len(filters) > 0 {
-		client.Query = append(client.Query, internal.Query{
-			Key:   "filter",
-			Value: strings.Join(filters, ","),
-		})
-	}
+//BulkReverseGeocodingAllContext is like BulkReverseGeocodingAll but carries the given context through to the requests
+func BulkReverseGeocodingAllContext(ctx context.Context, geocodes []Geocode, filters []string) []GeocodeResult {
+	return DefaultClient.BulkReverseGeocodingAllContext(ctx, geocodes, filters)
+}
 
-	if err := client.Request(http.MethodPost, "postcodes", payload); err != nil {
-		return nil, internal.RequestBuildError(err)
-	}
+//BulkReverseGeocodingStream Reverse geocodes the geolocations received from the given channel in bulk reverse
+//geocoding requests of up to 100 geolocations. The results are sent in completion order, correlated with the input
+//by their Index.
+func BulkReverseGeocodingStream(ctx context.Context, geocodes <-chan Geocode, filters []string) <-chan GeocodeResult {
+	return DefaultClient.BulkReverseGeocodingStream(ctx, geocodes, filters)
+}
 
-	response, responseError := client.Do()
-	if responseError != nil {
-		return nil, responseError
-	}
+//ReverseGeocodingAdaptive Returns the nearest postcodes for a given longitude and latitude, widening the search
+//radius step by step until n postcodes are found, then falling back to a wide search and finally to the nearest
+//outcodes
+func ReverseGeocodingAdaptive(geocode Geocode, n int64) (*AdaptiveResult, *model.ResponseError) {
+	return DefaultClient.ReverseGeocodingAdaptive(geocode, n)
+}
 
-	var data []model.Geocodes
-	if decodeErr := internal.ResponseDecoder(response.Body, &data); decodeErr != nil {
-		return nil, decodeErr
-	}
+//ReverseGeocodingAdaptiveContext is like ReverseGeocodingAdaptive but carries the given context through to the
+//requests
+func ReverseGeocodingAdaptiveContext(ctx context.Context, geocode Geocode, n int64) (*AdaptiveResult, *model.ResponseError) {
+	return DefaultClient.ReverseGeocodingAdaptiveContext(ctx, geocode, n)
+}
+
+//SearchRadius Returns the postcodes within the given radius in metres of the given centre, sorted nearest first,
+//beyond the 2,000m radius and 100 results limits of ReverseGeocoding. A max greater than 0 limits the number of results.
+func SearchRadius(centre Geocode, radius float64, max int) ([]model.Postcode, *model.ResponseError) {
+	return DefaultClient.SearchRadius(centre, radius, max)
+}
+
+//SearchRadiusContext is like SearchRadius but carries the given context through to the requests
+func SearchRadiusContext(ctx context.Context, centre Geocode, radius float64, max int) ([]model.Postcode, *model.ResponseError) {
+	return DefaultClient.SearchRadiusContext(ctx, centre, radius, max)
+}
+
+//SearchBoundingBox Returns the postcodes within the given bounding box, sorted nearest to its centre first. A max
+//greater than 0 limits the number of results.
+func SearchBoundingBox(box BoundingBox, max int) ([]model.Postcode, *model.ResponseError) {
+	return DefaultClient.SearchBoundingBox(box, max)
+}
 
-	return data, nil
+//SearchBoundingBoxContext is like SearchBoundingBox but carries the given context through to the requests
+func SearchBoundingBoxContext(ctx context.Context, box BoundingBox, max int) ([]model.Postcode, *model.ResponseError) {
+	return DefaultClient.SearchBoundingBoxContext(ctx, box, max)
 }
 
 //Query Submit a postcode query and receive a complete list of postcode matches and all associated
@@ -222,63 +173,64 @@ func BulkReverseGeocoding(geocodes Geocodes, filters []string) ([]model.Geocodes
 //
 //limit (not required) Limits number of postcodes matches to return. Defaults to 10. Needs to be less than 100.
 func Query(postcode string, limit *int64) ([]model.Postcode, *model.ResponseError) {
-	if limit != nil && *limit > 100 {
-		return nil, &model.ResponseError{
-			Status: http.StatusBadRequest,
-			Error:  "Maximum limit exceeded! Limit must be less than 100",
-		}
-	}
-	client := internal.Client()
+	return DefaultClient.Query(postcode, limit)
+}
 
-	client.Query = append(client.Query, internal.Query{
-		Key:   "q",
-		Value: postcode,
-	})
+//QueryContext is like Query but carries the given context through to the request
+func QueryContext(ctx context.Context, postcode string, limit *int64) ([]model.Postcode, *model.ResponseError) {
+	return DefaultClient.QueryContext(ctx, postcode, limit)
+}
 
-	if limit != nil {
-		client.Query = append(client.Query, internal.Query{
-			Key:   "limit",
-			Value: strconv.FormatInt(*limit, 10),
-		})
-	}
+//LookupAll Looks up any number of postcodes by splitting them into bulk lookups of up to 100 postcodes.
+//
+//	postcodes: List of postcodes
+//
+//	filter: (not required) A comma separated whitelist of attributes to be returned in the result object(s)
+//
+//Returns a result for each given postcode in the same order. Postcodes that are not found or belong to a failed
+//bulk lookup carry the error of their result instead of failing the whole run.
+func LookupAll(postcodes []string, filters []string) []LookupResult {
+	return DefaultClient.LookupAll(postcodes, filters)
+}
 
-	if err := client.Request(http.MethodGet, "postcodes", nil); err != nil {
-		return nil, internal.RequestBuildError(err)
-	}
+//LookupAllContext is like LookupAll but carries the given context through to the requests
+func LookupAllContext(ctx context.Context, postcodes []string, filters []string) []LookupResult {
+	return DefaultClient.LookupAllContext(ctx, postcodes, filters)
+}
 
-	response, responseError := client.Do()
-	if responseError != nil {
-		return nil, responseError
-	}
+//Enumerate Returns every live postcode of the given outcode (e.g. "RG12") or sector (e.g. "RG12 2") in sorted order
+func Enumerate(ctx context.Context, prefix string) ([]string, *model.ResponseError) {
+	return DefaultClient.Enumerate(ctx, prefix)
+}
 
-	data := new([]model.Postcode)
-	if decodeErr := internal.ResponseDecoder(response.Body, &data); decodeErr != nil {
-		return nil, decodeErr
-	}
+//EnumerateFunc is like Enumerate but calls fn with each postcode as soon as it is found, recording the progress in
+//the given state
+func EnumerateFunc(ctx context.Context, prefix string, state *EnumerateState, fn func(postcode string)) *model.ResponseError {
+	return DefaultClient.EnumerateFunc(ctx, prefix, state, fn)
+}
 
-	return *data, nil
+//Enrich Looks up the postcodes received from the given channel in bulk lookups of up to 100 postcodes. The results
+//are sent in input order. The returned channel is closed once the input channel is closed and all results are sent,
+//or once the context is done.
+func Enrich(ctx context.Context, postcodes <-chan string) <-chan Result {
+	return DefaultClient.Enrich(ctx, postcodes)
+}
+
+//EnrichReader is like Enrich but reads one postcode per line from the given reader
+func EnrichReader(ctx context.Context, reader io.Reader) <-chan Result {
+	return DefaultClient.EnrichReader(ctx, reader)
 }
 
 //Validation Convenience method to validate a postcode.
 //
 //Returns true or false (meaning valid or invalid respectively)
 func Validation(postcode string) (bool, *model.ResponseError) {
-	client := internal.Client()
-	if err := client.Request(http.MethodGet, fmt.Sprintf("postcodes/%s/validate", postcode), nil); err != nil {
-		return false, internal.RequestBuildError(err)
-	}
-
-	response, responseError := client.Do()
-	if responseError != nil {
-		return false, responseError
-	}
-
-	data := new(bool)
-	if decodeErr := internal.ResponseDecoder(response.Body, &data); decodeErr != nil {
-		return false, decodeErr
-	}
+	return DefaultClient.Validation(postcode)
+}
 
-	return *data, nil
+//ValidationContext is like Validation but carries the given context through to the request
+func ValidationContext(ctx context.Context, postcode string) (bool, *model.ResponseError) {
+	return DefaultClient.ValidationContext(ctx, postcode)
 }
 
 //NearestPostcode Returns nearest postcodes for a given postcode.
@@ -289,51 +241,12 @@ func Validation(postcode string) (bool, *model.ResponseError) {
 //
 //radius= (not required) Limits number of postcodes matches to return. Defaults to 100m. Needs to be less than 2,000m.
 func NearestPostcode(postcode string, limit, radius *int64) ([]model.Postcode, *model.ResponseError) {
-	if limit != nil && *limit > 100 {
-		return nil, &model.ResponseError{
-			Status: http.StatusBadRequest,
-			Error:  "Maximum limit exceeded! Limit must be less than 100",
-		}
-	}
-
-	if radius != nil && *radius > 2000 {
-		return nil, &model.ResponseError{
-			Status: http.StatusBadRequest,
-			Error:  "Maximum limit exceeded! Limit must be less than 100",
-		}
-	}
-
-	client := internal.Client()
-
-	if limit != nil {
-		client.Query = append(client.Query, internal.Query{
-			Key:   "limit",
-			Value: strconv.FormatInt(*limit, 10),
-		})
-	}
-
-	if radius != nil {
-		client.Query = append(client.Query, internal.Query{
-			Key:   "radius",
-			Value: strconv.FormatInt(*radius, 10),
-		})
-	}
-
-	if err := client.Request(http.MethodGet, fmt.Sprintf("postcodes/%s/nearest", postcode), nil); err != nil {
-		return nil, internal.RequestBuildError(err)
-	}
-
-	response, responseError := client.Do()
-	if responseError != nil {
-		return nil, responseError
-	}
-
-	data := new([]model.Postcode)
-	if decodeErr := internal.ResponseDecoder(response.Body, &data); decodeErr != nil {
-		return nil, decodeErr
-	}
+	return DefaultClient.NearestPostcode(postcode, limit, radius)
+}
 
-	return *data, nil
+//NearestPostcodeContext is like NearestPostcode but carries the given context through to the request
+func NearestPostcodeContext(ctx context.Context, postcode string, limit, radius *int64) ([]model.Postcode, *model.ResponseError) {
+	return DefaultClient.NearestPostcodeContext(ctx, postcode, limit, radius)
 }
 
 // Autocomplete Convenience method to return a list of matching postcodes.
@@ -341,38 +254,12 @@ func NearestPostcode(postcode string, limit, radius *int64) ([]model.Postcode, *
 //Optional Query Parameters
 //	limit= (not required) Limits number of postcodes matches to return. Defaults to 10. Needs to be less than 100.
 func Autocomplete(postcode string, limit *int64) ([]string, *model.ResponseError) {
+	return DefaultClient.Autocomplete(postcode, limit)
+}
 
-	if limit != nil && *limit > 100 {
-		return nil, &model.ResponseError{
-			Status: http.StatusBadRequest,
-			Error:  "Maximum limit exceeded! Limit must be less than 100",
-		}
-	}
-
-	client := internal.Client()
-
-	if limit != nil {
-		client.Query = append(client.Query, internal.Query{
-			Key:   "limit",
-			Value: strconv.FormatInt(*limit, 10),
-		})
-	}
-
-	if err := client.Request(http.MethodGet, fmt.Sprintf("postcodes/%s/autocomplete", postcode), nil); err != nil {
-		return nil, internal.RequestBuildError(err)
-	}
-
-	response, responseError := client.Do()
-	if responseError != nil {
-		return nil, responseError
-	}
-
-	data := new([]string)
-	if decodeErr := internal.ResponseDecoder(response.Body, &data); decodeErr != nil {
-		return nil, decodeErr
-	}
-
-	return *data, nil
+//AutocompleteContext is like Autocomplete but carries the given context through to the request
+func AutocompleteContext(ctx context.Context, postcode string, limit *int64) ([]string, *model.ResponseError) {
+	return DefaultClient.AutocompleteContext(ctx, postcode, limit)
 }
 
 //RandomPostcode Returns a random postcode and all available data for that postcode.
@@ -380,49 +267,23 @@ func Autocomplete(postcode string, limit *int64) ([]string, *model.ResponseError
 //Optional Query Parameters
 //	outcode= (not required) Filters random postcodes by outcode.
 func RandomPostcode(outCode *string) (*model.Postcode, *model.ResponseError) {
-	client := internal.Client()
-	if outCode != nil {
-		client.Query = append(client.Query, internal.Query{
-			Key:   "outcode",
-			Value: *outCode,
-		})
-	}
-	if err := client.Request(http.MethodGet, "random/postcodes", nil); err != nil {
-		return nil, internal.RequestBuildError(err)
-	}
-
-	response, responseError := client.Do()
-	if responseError != nil {
-		return nil, responseError
-	}
-
-	data := new(model.Postcode)
-	if decodeErr := internal.ResponseDecoder(response.Body, data); decodeErr != nil {
-		return nil, decodeErr
-	}
+	return DefaultClient.RandomPostcode(outCode)
+}
 
-	return data, nil
+//RandomPostcodeContext is like RandomPostcode but carries the given context through to the request
+func RandomPostcodeContext(ctx context.Context, outCode *string) (*model.Postcode, *model.ResponseError) {
+	return DefaultClient.RandomPostcodeContext(ctx, outCode)
 }
 
 //OutcodeLookup Geolocation data for the centroid of the outward code specified.
 //The outward code represents the first half of any postcode (separated by a space).
 func OutcodeLookup(outCode string) (*model.OutcodeData, *model.ResponseError) {
-	client := internal.Client()
-	if err := client.Request(http.MethodGet, fmt.Sprintf("outcodes/%s", outCode), nil); err != nil {
-		return nil, internal.RequestBuildError(err)
-	}
-
-	response, responseError := client.Do()
-	if responseError != nil {
-		return nil, responseError
-	}
-
-	data := new(model.OutcodeData)
-	if decodeErr := internal.ResponseDecoder(response.Body, data); decodeErr != nil {
-		return nil, decodeErr
-	}
+	return DefaultClient.OutcodeLookup(outCode)
+}
 
-	return data, nil
+//OutcodeLookupContext is like OutcodeLookup but carries the given context through to the request
+func OutcodeLookupContext(ctx context.Context, outCode string) (*model.OutcodeData, *model.ResponseError) {
+	return DefaultClient.OutcodeLookupContext(ctx, outCode)
 }
 
 //OutcodeReverseGeocoding Returns nearest outcodes for a given longitude and latitude.
@@ -432,47 +293,12 @@ func OutcodeLookup(outCode string) (*model.OutcodeData, *model.ResponseError) {
 //	radius= (not required) Limits number of postcodes matches to return. Defaults to 5,000m.
 //Needs to be less than 25,000m.
 func OutcodeReverseGeocoding(geocode Geocode) ([]model.OutcodeData, *model.ResponseError) {
-	if err := geocode.validate(); err != nil {
-		return nil, err
-	}
-	client := internal.Client()
-	client.Query = append(client.Query, internal.Query{
-		Key:   "lon",
-		Value: strconv.FormatFloat(geocode.Longitude, 'f', 20, 64),
-	})
-	client.Query = append(client.Query, internal.Query{
-		Key:   "lat",
-		Value: strconv.FormatFloat(geocode.Latitude, 'f', 20, 64),
-	})
-
-	if geocode.Limit > 0 {
-		client.Query = append(client.Query, internal.Query{
-			Key:   "limit",
-			Value: strconv.FormatInt(geocode.Limit, 10),
-		})
-	}
-	if geocode.Radius > 0 {
-		client.Query = append(client.Query, internal.Query{
-			Key:   "radius",
-			Value: strconv.FormatInt(geocode.Radius, 10),
-		})
-	}
-
-	if err := client.Request(http.MethodGet, "outcodes", nil); err != nil {
-		return nil, internal.RequestBuildError(err)
-	}
-
-	response, responseError := client.Do()
-	if responseError != nil {
-		return nil, responseError
-	}
-
-	data := new([]model.OutcodeData)
-	if decodeErr := internal.ResponseDecoder(response.Body, data); decodeErr != nil {
-		return nil, decodeErr
-	}
+	return DefaultClient.OutcodeReverseGeocoding(geocode)
+}
 
-	return *data, nil
+//OutcodeReverseGeocodingContext is like OutcodeReverseGeocoding but carries the given context through to the request
+func OutcodeReverseGeocodingContext(ctx context.Context, geocode Geocode) ([]model.OutcodeData, *model.ResponseError) {
+	return DefaultClient.OutcodeReverseGeocodingContext(ctx, geocode)
 }
 
 //NearestOutcode Returns nearest outcodes for a given outcode.
@@ -482,97 +308,44 @@ func OutcodeReverseGeocoding(geocode Geocode) ([]model.OutcodeData, *model.Respo
 //	radius= (not required) Limits number of postcodes matches to return. Defaults to 5,000m.
 //Needs to be less than 25,000m.
 func NearestOutcode(outCode string, limit, radius *int64) ([]model.OutcodeData, *model.ResponseError) {
-	client := internal.Client()
-	if limit != nil && *limit > 0 {
-		client.Query = append(client.Query, internal.Query{
-			Key:   "limit",
-			Value: strconv.FormatInt(*limit, 10),
-		})
-	}
-	if radius != nil && *radius > 0 {
-		client.Query = append(client.Query, internal.Query{
-			Key:   "radius",
-			Value: strconv.FormatInt(*radius, 10),
-		})
-	}
-
-	if err := client.Request(http.MethodGet, fmt.Sprintf("outcodes/%s/nearest", outCode), nil); err != nil {
-		return nil, internal.RequestBuildError(err)
-	}
-
-	response, responseError := client.Do()
-	if responseError != nil {
-		return nil, responseError
-	}
-
-	data := new([]model.OutcodeData)
-	if decodeErr := internal.ResponseDecoder(response.Body, data); decodeErr != nil {
-		return nil, decodeErr
-	}
+	return DefaultClient.NearestOutcode(outCode, limit, radius)
+}
 
-	return *data, nil
+//NearestOutcodeContext is like NearestOutcode but carries the given context through to the request
+func NearestOutcodeContext(ctx context.Context, outCode string, limit, radius *int64) ([]model.OutcodeData, *model.ResponseError) {
+	return DefaultClient.NearestOutcodeContext(ctx, outCode, limit, radius)
 }
 
 //ScottishPostcodeLookup Lookup a Scottish postcode. Returns SPD data associated with postcode.
 //At the moment this is just Scottish Parliamentary Constituency.
 func ScottishPostcodeLookup(postcode string) (*model.ScottishPostcodeData, *model.ResponseError) {
-	client := internal.Client()
-	if err := client.Request(http.MethodGet, fmt.Sprintf("scotland/postcodes/%s", postcode), nil); err != nil {
-		return nil, internal.RequestBuildError(err)
-	}
-
-	response, responseError := client.Do()
-	if responseError != nil {
-		return nil, responseError
-	}
-
-	data := new(model.ScottishPostcodeData)
-	if decodeErr := internal.ResponseDecoder(response.Body, data); decodeErr != nil {
-		return nil, decodeErr
-	}
+	return DefaultClient.ScottishPostcodeLookup(postcode)
+}
 
-	return data, nil
+//ScottishPostcodeLookupContext is like ScottishPostcodeLookup but carries the given context through to the request
+func ScottishPostcodeLookupContext(ctx context.Context, postcode string) (*model.ScottishPostcodeData, *model.ResponseError) {
+	return DefaultClient.ScottishPostcodeLookupContext(ctx, postcode)
 }
 
 //TerminatedPostcodeLookup Lookup a terminated postcode. Returns the postcode, year and month of termination.
 func TerminatedPostcodeLookup(postcode string) (*model.TerminatedPostcode, *model.ResponseError) {
-	client := internal.Client()
-	if err := client.Request(http.MethodGet, fmt.Sprintf("terminated_postcodes/%s", postcode), nil); err != nil {
-		return nil, internal.RequestBuildError(err)
-	}
-
-	response, responseError := client.Do()
-	if responseError != nil {
-		return nil, responseError
-	}
-
-	data := new(model.TerminatedPostcode)
-	if decodeErr := internal.ResponseDecoder(response.Body, data); decodeErr != nil {
-		return nil, decodeErr
-	}
+	return DefaultClient.TerminatedPostcodeLookup(postcode)
+}
 
-	return data, nil
+//TerminatedPostcodeLookupContext is like TerminatedPostcodeLookup but carries the given context through to the request
+func TerminatedPostcodeLookupContext(ctx context.Context, postcode string) (*model.TerminatedPostcode, *model.ResponseError) {
+	return DefaultClient.TerminatedPostcodeLookupContext(ctx, postcode)
 }
 
 //PlaceLookup Find a place by OSGB code (e.g. "osgb4000000074564391").
 //Returns all available data if found.
 func PlaceLookup(osgbCode string) (*model.Place, *model.ResponseError) {
-	client := internal.Client()
-	if err := client.Request(http.MethodGet, fmt.Sprintf("places/%s", osgbCode), nil); err != nil {
-		return nil, internal.RequestBuildError(err)
-	}
-
-	response, responseError := client.Do()
-	if responseError != nil {
-		return nil, responseError
-	}
-
-	data := new(model.Place)
-	if decodeErr := internal.ResponseDecoder(response.Body, data); decodeErr != nil {
-		return nil, decodeErr
-	}
+	return DefaultClient.PlaceLookup(osgbCode)
+}
 
-	return data, nil
+//PlaceLookupContext is like PlaceLookup but carries the given context through to the request
+func PlaceLookupContext(ctx context.Context, osgbCode string) (*model.Place, *model.ResponseError) {
+	return DefaultClient.PlaceLookupContext(ctx, osgbCode)
 }
 
 //PlaceQuery Submit a place query and receive a complete list of places matches and associated data.
@@ -581,62 +354,22 @@ func PlaceLookup(osgbCode string) (*model.Place, *model.ResponseError) {
 //	query: Place name to query and receive a complete list of places matches and associated data.
 //	limit: (not required) Limits number of postcodes matches to return. Defaults to 10. Needs to be less than 100.
 func PlaceQuery(query string, limit *int64) ([]model.Place, *model.ResponseError) {
-	if limit != nil && *limit > 100 {
-		return nil, &model.ResponseError{
-			Status: http.StatusBadRequest,
-			Error:  "Maximum limit exceeded! Limit must be less than 100",
-		}
-	}
-
-	client := internal.Client()
-
-	client.Query = append(client.Query, internal.Query{
-		Key:   "q",
-		Value: query,
-	})
-
-	if limit != nil && *limit > 0 {
-		client.Query = append(client.Query, internal.Query{
-			Key:   "limit",
-			Value: strconv.FormatInt(*limit, 10),
-		})
-	}
-
-	if err := client.Request(http.MethodGet, fmt.Sprintf("places"), nil); err != nil {
-		return nil, internal.RequestBuildError(err)
-	}
-
-	response, responseError := client.Do()
-	if responseError != nil {
-		return nil, responseError
-	}
-
-	data := new([]model.Place)
-	if decodeErr := internal.ResponseDecoder(response.Body, data); decodeErr != nil {
-		return nil, decodeErr
-	}
+	return DefaultClient.PlaceQuery(query, limit)
+}
 
-	return *data, nil
+//PlaceQueryContext is like PlaceQuery but carries the given context through to the request
+func PlaceQueryContext(ctx context.Context, query string, limit *int64) ([]model.Place, *model.ResponseError) {
+	return DefaultClient.PlaceQueryContext(ctx, query, limit)
 }
 
 //RandomPlace Returns a random place and all associated data
 func RandomPlace() (*model.Place, *model.ResponseError) {
-	client := internal.Client()
-	if err := client.Request(http.MethodGet, "random/places", nil); err != nil {
-		return nil, internal.RequestBuildError(err)
-	}
-
-	response, responseError := client.Do()
-	if responseError != nil {
-		return nil, responseError
-	}
-
-	data := new(model.Place)
-	if decodeErr := internal.ResponseDecoder(response.Body, data); decodeErr != nil {
-		return nil, decodeErr
-	}
+	return DefaultClient.RandomPlace()
+}
 
-	return data, nil
+//RandomPlaceContext is like RandomPlace but carries the given context through to the request
+func RandomPlaceContext(ctx context.Context) (*model.Place, *model.ResponseError) {
+	return DefaultClient.RandomPlaceContext(ctx)
 }
 
 func (p Postcodes) json() ([]byte, error) {
@@ -645,16 +378,10 @@ func (p Postcodes) json() ([]byte, error) {
 
 func (p Postcodes) validate() *model.ResponseError {
 	if len(p.Postcodes) == 0 {
-		return &model.ResponseError{
-			Status: http.StatusBadRequest,
-			Error:  "minimum of 1 postcode required!",
-		}
+		return internal.ValidationError("minimum of 1 postcode required!")
 	}
-	if len(p.Postcodes) > 100 {
-		return &model.ResponseError{
-			Status: http.StatusBadRequest,
-			Error:  "Maximum postcode limit exceeded! Maximum of 100 postcodes",
-		}
+	if len(p.Postcodes) > maxBulkSize {
+		return internal.ValidationError("Maximum postcode limit exceeded! Maximum of 100 postcodes")
 	}
 	return nil
 }
@@ -663,12 +390,29 @@ func (g Geocode) json() ([]byte, error) {
 	return json.Marshal(g)
 }
 
+func (g Geocode) query() []internal.Query {
+	query := []internal.Query{
+		{Key: "lon", Value: strconv.FormatFloat(g.Longitude, 'f', 20, 64)},
+		{Key: "lat", Value: strconv.FormatFloat(g.Latitude, 'f', 20, 64)},
+	}
+	if g.Limit > 0 {
+		query = append(query, internal.Query{
+			Key:   "limit",
+			Value: strconv.FormatInt(g.Limit, 10),
+		})
+	}
+	if g.Radius > 0 {
+		query = append(query, internal.Query{
+			Key:   "radius",
+			Value: strconv.FormatInt(g.Radius, 10),
+		})
+	}
+	return query
+}
+
 func (g Geocode) validate() *model.ResponseError {
 	if g.Latitude == 0.0 || g.Longitude == 0.0 {
-		return &model.ResponseError{
-			Status: http.StatusBadRequest,
-			Error:  "Latitude and Longitude must be defined",
-		}
+		return internal.ValidationError("Latitude and Longitude must be defined")
 	}
 	return nil
 }
@@ -680,17 +424,11 @@ func (gs Geocodes) json() ([]byte, error) {
 
 func (gs Geocodes) validate() *model.ResponseError {
 	if len(gs.Geolocations) == 0 {
-		return &model.ResponseError{
-			Status: http.StatusBadRequest,
-			Error:  "minimum of 1 geolocations required!",
-		}
+		return internal.ValidationError("minimum of 1 geolocations required!")
 	}
 
-	if len(gs.Geolocations) > 100 {
-		return &model.ResponseError{
-			Status: http.StatusBadRequest,
-			Error:  "Maximum geolocations limit exceeded! Maximum of 100 geolocations",
-		}
+	if len(gs.Geolocations) > maxBulkSize {
+		return internal.ValidationError("Maximum geolocations limit exceeded! Maximum of 100 geolocations")
 	}
 
 	for _, g := range gs.Geolocations {
@@ -0,0 +1,113 @@
+package postcodetest
+
+import (
+	"github.com/razorcorp/postcode-sdk-go/model"
+	"github.com/razorcorp/postcode-sdk-go/postcode"
+	"math"
+	"strings"
+)
+
+const earthRadius = 6371008.8
+
+type (
+	//Dataset records served by a Server. Outcodes without a record of their own are derived from their postcodes, with
+	//the centroid of their coordinates and the distinct administrative areas.
+	Dataset struct {
+		Postcodes  []model.Postcode
+		Outcodes   []model.OutcodeData
+		Scottish   []model.ScottishPostcodeData
+		Terminated []model.TerminatedPostcode
+		Places     []model.Place
+	}
+)
+
+//SampleDataset returns a small dataset of postcodes in Reading, Bracknell, Westminster and Edinburgh, with a terminated
+//postcode and a place
+func SampleDataset() Dataset {
+	return Dataset{
+		Postcodes: []model.Postcode{
+			samplePostcode("RG1 1AF", 51.456813, -0.971396, "England", "Reading", "Abbey"),
+			samplePostcode("RG1 1AZ", 51.455915, -0.969641, "England", "Reading", "Abbey"),
+			samplePostcode("RG1 2AG", 51.454196, -0.974587, "England", "Reading", "Abbey"),
+			samplePostcode("RG12 2PE", 51.411893, -0.751853, "England", "Bracknell Forest", "Great Hollands North"),
+			samplePostcode("SW1A 1AA", 51.501009, -0.141588, "England", "Westminster", "St James's"),
+			samplePostcode("SW1A 2AA", 51.503540, -0.127695, "England", "Westminster", "St James's"),
+			samplePostcode("EH1 1YZ", 55.950880, -3.189820, "Scotland", "City of Edinburgh", "City Centre"),
+		},
+		Scottish: []model.ScottishPostcodeData{
+			{
+				Postcode:                          "EH1 1YZ",
+				ScottishParliamentaryConstituency: "Edinburgh Central",
+				Codes:                             model.ScottishCodes{ScottishParliamentaryConstituency: "S16000104"},
+			},
+		},
+		Terminated: []model.TerminatedPostcode{
+			{Postcode: "RG1 1AB", YearTerminated: 1999, MonthTerminated: 6, Longitude: -0.970862, Latitude: 51.456288},
+		},
+		Places: []model.Place{
+			{
+				Code:              "osgb4000000074564391",
+				Name1:             "Reading",
+				LocalType:         "Town",
+				Outcode:           "RG1",
+				CountyUnitary:     "Reading",
+				CountyUnitaryType: "UnitaryAuthority",
+				Region:            "South East",
+				Country:           "England",
+				Longitude:         -0.971111,
+				Latitude:          51.455556,
+			},
+		},
+	}
+}
+
+func samplePostcode(code string, latitude, longitude float64, country, district, ward string) model.Postcode {
+	return model.Postcode{
+		Postcode:      code,
+		Quality:       1,
+		Country:       country,
+		AdminDistrict: district,
+		AdminWard:     ward,
+		Latitude:      latitude,
+		Longitude:     longitude,
+	}
+}
+
+//canonical returns the canonical form of the given postcode, e.g. "RG1 2AG" for "rg12ag", or the postcode in upper
+//case if it is malformed
+func canonical(code string) string {
+	if parts, err := postcode.Parse(code); err == nil {
+		return parts.String()
+	}
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+//compact returns the given postcode or prefix in upper case without white space, e.g. "RG12" for "rg1 2"
+func compact(code string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(code), ""))
+}
+
+//appendDistinct appends the value to the list unless empty or already present
+func appendDistinct(list []string, value string) []string {
+	if value == "" {
+		return list
+	}
+	for _, item := range list {
+		if item == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
+
+//haversine returns the great-circle distance in metres between the two given coordinates
+func haversine(latitude1, longitude1, latitude2, longitude2 float64) float64 {
+	phi1 := latitude1 * math.Pi / 180
+	phi2 := latitude2 * math.Pi / 180
+	deltaPhi := (latitude2 - latitude1) * math.Pi / 180
+	deltaLambda := (longitude2 - longitude1) * math.Pi / 180
+
+	a := math.Sin(deltaPhi/2)*math.Sin(deltaPhi/2) +
+		math.Cos(phi1)*math.Cos(phi2)*math.Sin(deltaLambda/2)*math.Sin(deltaLambda/2)
+	return 2 * earthRadius * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
@@ -0,0 +1,263 @@
+package postcodetest
+
+import (
+	"github.com/razorcorp/postcode-sdk-go/postcode/internal"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	//FaultLatency delays the response by the latency of the fault, then answers normally
+	FaultLatency FaultKind = "latency"
+
+	//FaultStatus responds with the status of the fault, 500 by default, and an error envelope. Together with
+	//RetryAfter it simulates rate limiting, e.g. Status 429
+	FaultStatus FaultKind = "status"
+
+	//FaultTruncated sends the first half of the response body, announcing its full length, then closes the connection
+	FaultTruncated FaultKind = "truncated"
+
+	//FaultInvalidJSON responds with a malformed JSON body and the status of the fault, 200 by default
+	FaultInvalidJSON FaultKind = "invalid_json"
+
+	//FaultReset resets the connection without responding. Note that http.Transport transparently retries idempotent
+	//requests once when a reused connection fails this way, which counts as another call.
+	FaultReset FaultKind = "reset"
+
+	//FaultSlowDrip sends the response body in chunks of ChunkSize bytes every Interval
+	FaultSlowDrip FaultKind = "slow_drip"
+)
+
+const (
+	defaultDripChunkSize = 64
+	defaultDripInterval  = 100 * time.Millisecond
+)
+
+//invalidJSON body of FaultInvalidJSON responses
+const invalidJSON = `{"status":200,"result":[{"postcode":`
+
+type (
+	//FaultKind how the Server misbehaves for a request matching a Fault
+	FaultKind string
+
+	//Fault makes the Server misbehave for matching requests. The calls of each method and endpoint are counted from
+	//1. A fault matches the calls after the first After ones, up to Times calls if set, and only every Every-th of them
+	//starting with the first if set. Every call of every endpoint matches the zero Fault.
+	//
+	//	// the first two lookups are rate limited, then every third one fails
+	//	fake.Inject(
+	//		postcodetest.Fault{Kind: postcodetest.FaultStatus, Endpoint: "postcodes/:postcode", Times: 2,
+	//			Status: http.StatusTooManyRequests, RetryAfter: time.Second},
+	//		postcodetest.Fault{Kind: postcodetest.FaultStatus, Endpoint: "postcodes/:postcode", After: 2, Every: 3,
+	//			Status: http.StatusBadGateway},
+	//	)
+	Fault struct {
+		Kind FaultKind
+
+		//Method of the matching requests, e.g. POST. Any method when empty
+		Method string
+
+		//Endpoint of the matching requests with the postcodes, outcodes and places replaced with placeholders, e.g.
+		//postcodes/:postcode/validate or outcodes/:outcode. Any endpoint when empty
+		Endpoint string
+
+		//After number of matching calls answered normally before the fault applies
+		After int
+
+		//Times number of calls the fault applies to. Unlimited when 0
+		Times int
+
+		//Every applies the fault only to every n-th call, starting with the first, simulating intermittent failures
+		Every int
+
+		//Latency delays the response of any kind of fault, plus a random duration up to Jitter
+		Latency time.Duration
+		Jitter  time.Duration
+
+		//Status of FaultStatus and FaultInvalidJSON responses
+		Status int
+
+		//RetryAfter sends a Retry-After header in seconds with the response
+		RetryAfter time.Duration
+
+		//ChunkSize and Interval of FaultSlowDrip responses. Default to 64 bytes and 100ms
+		ChunkSize int
+		Interval  time.Duration
+	}
+)
+
+//Inject adds the given faults to the Server. The first fault matching a call applies.
+func (s *Server) Inject(faults ...Fault) {
+	s.faultsMu.Lock()
+	defer s.faultsMu.Unlock()
+	s.faults = append(s.faults, faults...)
+}
+
+//ClearFaults removes the injected faults and resets the call counts
+func (s *Server) ClearFaults() {
+	s.faultsMu.Lock()
+	defer s.faultsMu.Unlock()
+	s.faults = nil
+	s.calls = make(map[string]int)
+}
+
+//Calls returns the number of requests received with the given method and endpoint, e.g. GET postcodes/:postcode.
+//Requests of every method and endpoint are counted when both are empty.
+func (s *Server) Calls(method, endpoint string) int {
+	s.faultsMu.Lock()
+	defer s.faultsMu.Unlock()
+	if method == "" && endpoint == "" {
+		total := 0
+		for _, n := range s.calls {
+			total += n
+		}
+		return total
+	}
+	return s.calls[method+" "+endpoint]
+}
+
+//fault counts the given call and returns the first fault matching it, or nil
+func (s *Server) fault(r *http.Request) *Fault {
+	path := internal.Endpoint(r.URL.Path)
+
+	s.faultsMu.Lock()
+	defer s.faultsMu.Unlock()
+	s.calls[r.Method+" "+path]++
+	call := s.calls[r.Method+" "+path]
+
+	for i := range s.faults {
+		fault := s.faults[i]
+		if fault.matches(r.Method, path, call) {
+			return &fault
+		}
+	}
+	return nil
+}
+
+//matches reports whether the fault applies to the given call of the method and endpoint
+func (f Fault) matches(method, path string, call int) bool {
+	if (f.Method != "" && !strings.EqualFold(f.Method, method)) || (f.Endpoint != "" && strings.Trim(f.Endpoint, "/") != path) {
+		return false
+	}
+	n := call - f.After
+	if n < 1 || (f.Times > 0 && n > f.Times) {
+		return false
+	}
+	return f.Every <= 1 || (n-1)%f.Every == 0
+}
+
+//inject answers the given request according to the fault
+func (s *Server) inject(w http.ResponseWriter, r *http.Request, fault Fault) {
+	if !s.delay(r, fault) {
+		return
+	}
+	if fault.RetryAfter > 0 {
+		seconds := (fault.RetryAfter + time.Second - 1) / time.Second
+		w.Header().Set("Retry-After", strconv.FormatInt(int64(seconds), 10))
+	}
+
+	switch fault.Kind {
+	case FaultStatus:
+		status := fault.status(http.StatusInternalServerError)
+		write(w, status, encode(errorEnvelope{Status: status, Error: http.StatusText(status)}))
+	case FaultTruncated:
+		status, body := s.respond(r)
+		writeHeader(w, status, len(body))
+		w.Write(body[:len(body)/2])
+	case FaultInvalidJSON:
+		write(w, fault.status(http.StatusOK), []byte(invalidJSON))
+	case FaultReset:
+		reset(w)
+	case FaultSlowDrip:
+		status, body := s.respond(r)
+		s.drip(w, r, fault, status, body)
+	default:
+		status, body := s.respond(r)
+		write(w, status, body)
+	}
+}
+
+//delay waits for the latency of the fault, reporting false if the client gave up in the meantime
+func (s *Server) delay(r *http.Request, fault Fault) bool {
+	latency := fault.Latency
+	if fault.Jitter > 0 {
+		s.randomMu.Lock()
+		latency += time.Duration(s.random.Int63n(int64(fault.Jitter)))
+		s.randomMu.Unlock()
+	}
+	if latency <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(latency)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-r.Context().Done():
+		return false
+	}
+}
+
+//drip writes the body in chunks at the interval of the fault
+func (s *Server) drip(w http.ResponseWriter, r *http.Request, fault Fault, status int, body []byte) {
+	size, interval := fault.ChunkSize, fault.Interval
+	if size <= 0 {
+		size = defaultDripChunkSize
+	}
+	if interval <= 0 {
+		interval = defaultDripInterval
+	}
+
+	writeHeader(w, status, len(body))
+	flusher, _ := w.(http.Flusher)
+	for len(body) > 0 {
+		n := size
+		if n > len(body) {
+			n = len(body)
+		}
+		if _, err := w.Write(body[:n]); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		body = body[n:]
+		if len(body) == 0 {
+			return
+		}
+
+		select {
+		case <-time.After(interval):
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+//status returns the status of the fault, or the given default
+func (f Fault) status(defaultStatus int) int {
+	if f.Status > 0 {
+		return f.Status
+	}
+	return defaultStatus
+}
+
+//reset closes the connection of the response with a TCP reset
+func reset(w http.ResponseWriter) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		panic(http.ErrAbortHandler)
+	}
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		panic(http.ErrAbortHandler)
+	}
+	if tcp, ok := conn.(*net.TCPConn); ok {
+		tcp.SetLinger(0)
+	}
+	conn.Close()
+}
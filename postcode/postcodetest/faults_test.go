@@ -0,0 +1,97 @@
+package postcodetest_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/razorcorp/postcode-sdk-go/model"
+	"github.com/razorcorp/postcode-sdk-go/postcode"
+	"github.com/razorcorp/postcode-sdk-go/postcode/postcodetest"
+)
+
+func TestFaultKinds(t *testing.T) {
+	tests := []struct {
+		name  string
+		fault postcodetest.Fault
+		want  error
+	}{
+		{name: "status", fault: postcodetest.Fault{Kind: postcodetest.FaultStatus}, want: model.ErrServer},
+		{name: "bad gateway", fault: postcodetest.Fault{Kind: postcodetest.FaultStatus, Status: http.StatusBadGateway}, want: model.ErrServer},
+		{name: "rate limited", fault: postcodetest.Fault{Kind: postcodetest.FaultStatus, Status: http.StatusTooManyRequests, RetryAfter: time.Second}, want: model.ErrRateLimited},
+		{name: "invalid json", fault: postcodetest.Fault{Kind: postcodetest.FaultInvalidJSON}, want: model.ErrDecode},
+		{name: "truncated", fault: postcodetest.Fault{Kind: postcodetest.FaultTruncated}, want: model.ErrTransport},
+		{name: "reset", fault: postcodetest.Fault{Kind: postcodetest.FaultReset}, want: model.ErrTransport},
+		{name: "latency", fault: postcodetest.Fault{Kind: postcodetest.FaultLatency, Latency: time.Second}, want: model.ErrTransport},
+		{name: "slow drip", fault: postcodetest.Fault{Kind: postcodetest.FaultSlowDrip, ChunkSize: 8, Interval: 50 * time.Millisecond}, want: model.ErrTransport},
+	}
+	categories := []error{model.ErrServer, model.ErrRateLimited, model.ErrDecode, model.ErrTransport}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fake := newServer(t)
+			fake.Inject(test.fault)
+			client := fake.Client(postcode.WithTimeout(200 * time.Millisecond))
+
+			_, err := client.Lookup("RG1 1AF")
+			if err == nil {
+				t.Fatal("Lookup() error = nil")
+			}
+			for _, category := range categories {
+				if got := errors.Is(err, category); got != (category == test.want) {
+					t.Errorf("errors.Is(%v, %v) = %v, want %v", err, category, got, !got)
+				}
+			}
+		})
+	}
+}
+
+func TestFaultMatching(t *testing.T) {
+	fake := newServer(t)
+	fake.Inject(postcodetest.Fault{Kind: postcodetest.FaultStatus, Method: http.MethodGet, Endpoint: "postcodes/:postcode", After: 1, Times: 4, Every: 2})
+	client := fake.Client()
+
+	var failed []int
+	for call := 1; call <= 7; call++ {
+		if _, err := client.Lookup("RG1 1AF"); err != nil {
+			failed = append(failed, call)
+		}
+	}
+	if len(failed) != 2 || failed[0] != 2 || failed[1] != 4 {
+		t.Errorf("failed calls = %v, want [2 4]", failed)
+	}
+	if _, err := client.OutcodeLookup("RG1"); err != nil {
+		t.Errorf("OutcodeLookup() error = %v, want the fault limited to postcodes/:postcode", err)
+	}
+	if calls := fake.Calls(http.MethodGet, "postcodes/:postcode"); calls != 7 {
+		t.Errorf("Calls() = %d, want 7", calls)
+	}
+
+	fake.ClearFaults()
+	if _, err := client.Lookup("RG1 1AF"); err != nil {
+		t.Errorf("Lookup() after ClearFaults error = %v", err)
+	}
+	if calls := fake.Calls("", ""); calls != 1 {
+		t.Errorf("Calls() after ClearFaults = %d, want 1", calls)
+	}
+}
+
+func TestFaultRetried(t *testing.T) {
+	fake := newServer(t)
+	fake.Inject(postcodetest.Fault{Kind: postcodetest.FaultStatus, Status: http.StatusServiceUnavailable, Times: 2})
+	client := fake.Client(postcode.WithRetryPolicy(postcode.RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  time.Millisecond,
+		MaxBackoff:  10 * time.Millisecond,
+		Statuses:    []int{http.StatusServiceUnavailable},
+	}))
+
+	data, err := client.Lookup("RG1 1AF")
+	if err != nil || data.Postcode != "RG1 1AF" {
+		t.Fatalf("Lookup() = %+v, %v", data, err)
+	}
+	if calls := fake.Calls(http.MethodGet, "postcodes/:postcode"); calls != 3 {
+		t.Errorf("Calls() = %d, want 3", calls)
+	}
+}
@@ -0,0 +1,429 @@
+package postcodetest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/razorcorp/postcode-sdk-go/model"
+	"github.com/razorcorp/postcode-sdk-go/postcode"
+	"github.com/razorcorp/postcode-sdk-go/postcode/internal"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//ErrUnrecorded is returned by a strict Replayer for requests without a fixture. The error returned also wraps
+//model.ErrRequest, so the Client fails the request without retrying it
+var ErrUnrecorded = errors.New("postcodetest: unrecorded request")
+
+var (
+	_ postcode.Doer = (*Recorder)(nil)
+	_ postcode.Doer = (*Replayer)(nil)
+)
+
+type (
+	//Recorder is a postcode.Doer executing the requests with another Doer and recording every response into a
+	//fixtures directory, one JSON file per request, to be replayed by a Replayer. Recording the same request again
+	//overwrites its fixture. Transport errors are not recorded. Recorder is safe for concurrent use.
+	//
+	//	recorder, err := postcodetest.NewRecorder("testdata/fixtures", nil)
+	//	client := postcode.NewClient(postcode.WithDoer(recorder))
+	Recorder struct {
+		dir  string
+		next postcode.Doer
+		mu   sync.Mutex
+	}
+
+	//ReplayOptions configures a Replayer
+	ReplayOptions struct {
+		//Strict fails every request without a fixture with ErrUnrecorded
+		Strict bool
+
+		//Fallback Doer executing the requests without a fixture when not strict, e.g. the client of a Server. They are
+		//answered with a 404 error envelope when nil
+		Fallback postcode.Doer
+	}
+
+	//Replayer is a postcode.Doer answering the requests from the fixtures recorded by a Recorder, without network
+	//access. Requests are matched by a normalised key ignoring the base URL, the order of the query parameters, the
+	//case and spacing of the postcodes in the path and the formatting of numbers. Bulk POST requests match regardless
+	//of the order of their postcodes or geolocations, with the results replayed in the order requested. Replayer is
+	//safe for concurrent use.
+	Replayer struct {
+		fixtures   map[string]fixture
+		options    ReplayOptions
+		mu         sync.Mutex
+		unrecorded []string
+	}
+
+	//fixture recorded request and response
+	fixture struct {
+		Key      string          `json:"key"`
+		Request  fixtureRequest  `json:"request"`
+		Response fixtureResponse `json:"response"`
+	}
+
+	fixtureRequest struct {
+		Method string          `json:"method"`
+		URL    string          `json:"url"`
+		Body   json.RawMessage `json:"body,omitempty"`
+	}
+
+	//fixtureResponse recorded response with its body as JSON, or as text if it is not valid JSON
+	fixtureResponse struct {
+		Status int             `json:"status"`
+		Header http.Header     `json:"header,omitempty"`
+		Body   json.RawMessage `json:"body,omitempty"`
+		Text   string          `json:"text,omitempty"`
+	}
+
+	//requestKey normalised request and the keys of the items of a bulk request body, in order
+	requestKey struct {
+		key   string
+		items []string
+	}
+)
+
+//NewRecorder returns a Recorder writing its fixtures to the given directory, creating it when missing. Requests are
+//executed with the given Doer, or a default HTTP client when nil.
+func NewRecorder(dir string, next postcode.Doer) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if next == nil {
+		next = new(http.Client)
+	}
+	return &Recorder{dir: dir, next: next}, nil
+}
+
+//Do executes the request and records its response
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := r.next.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	responseBody, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	key := newRequestKey(req.Method, req.URL, body)
+	recorded := fixture{
+		Key: key.key,
+		Request: fixtureRequest{
+			Method: req.Method,
+			URL:    req.URL.String(),
+		},
+		Response: fixtureResponse{
+			Status: response.StatusCode,
+			Header: response.Header.Clone(),
+		},
+	}
+	if len(body) > 0 {
+		if !json.Valid(body) {
+			return nil, errors.New("postcodetest: recording fixture: request body is not JSON")
+		}
+		recorded.Request.Body = json.RawMessage(body)
+	}
+	if json.Valid(responseBody) {
+		recorded.Response.Body = json.RawMessage(responseBody)
+	} else {
+		recorded.Response.Text = string(responseBody)
+	}
+
+	if err := r.save(key.key, recorded); err != nil {
+		return nil, fmt.Errorf("postcodetest: recording fixture: %w", err)
+	}
+	return response, nil
+}
+
+//save writes the fixture atomically
+func (r *Recorder) save(key string, recorded fixture) error {
+	encoded, err := json.MarshalIndent(recorded, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	path := filepath.Join(r.dir, fixtureName(key))
+	temporary := path + ".tmp"
+	if err := os.WriteFile(temporary, encoded, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(temporary, path)
+}
+
+//NewReplayer returns a Replayer answering from the fixtures of the given directory
+func NewReplayer(dir string, options ReplayOptions) (*Replayer, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	fixtures := make(map[string]fixture, len(paths))
+	for _, path := range paths {
+		encoded, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var recorded fixture
+		if err := json.Unmarshal(encoded, &recorded); err != nil {
+			return nil, fmt.Errorf("postcodetest: fixture %s: %w", path, err)
+		}
+		requestURL, err := url.Parse(recorded.Request.URL)
+		if err != nil {
+			return nil, fmt.Errorf("postcodetest: fixture %s: %w", path, err)
+		}
+		// keys are recomputed so fixtures stay valid when the normalisation changes
+		key := newRequestKey(recorded.Request.Method, requestURL, recorded.Request.Body)
+		fixtures[key.key] = recorded
+	}
+	return &Replayer{fixtures: fixtures, options: options}, nil
+}
+
+//Do answers the request from its fixture
+func (r *Replayer) Do(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	key := newRequestKey(req.Method, req.URL, body)
+	recorded, ok := r.fixtures[key.key]
+	if !ok {
+		r.mu.Lock()
+		r.unrecorded = append(r.unrecorded, key.key)
+		r.mu.Unlock()
+
+		switch {
+		case r.options.Strict:
+			return nil, fmt.Errorf("%w: %s (%w)", ErrUnrecorded, key.key, model.ErrRequest)
+		case r.options.Fallback != nil:
+			return r.options.Fallback.Do(req)
+		}
+		status := http.StatusNotFound
+		return response(req, status, nil, encode(errorEnvelope{Status: status, Error: "No fixture recorded for " + key.key})), nil
+	}
+
+	responseBody := []byte(recorded.Response.Text)
+	if len(recorded.Response.Body) > 0 {
+		responseBody = recorded.Response.Body
+	}
+	if len(key.items) > 0 {
+		recordedURL, _ := url.Parse(recorded.Request.URL)
+		recordedKey := newRequestKey(recorded.Request.Method, recordedURL, recorded.Request.Body)
+		responseBody = reorder(responseBody, recordedKey.items, key.items)
+	}
+	return response(req, recorded.Response.Status, recorded.Response.Header, responseBody), nil
+}
+
+//Unrecorded returns the keys of the requests received without a fixture, in order
+func (r *Replayer) Unrecorded() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.unrecorded...)
+}
+
+//newRequestKey returns the normalised key of the given request
+func newRequestKey(method string, requestURL *url.URL, body []byte) requestKey {
+	path := internal.ReplacePathParameters(requestURL.Path, func(segment, _ string) string {
+		return compact(segment)
+	})
+
+	query := requestURL.Query()
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parameters := make([]string, 0, len(names))
+	for _, name := range names {
+		for _, value := range query[name] {
+			parameters = append(parameters, name+"="+normaliseValue(name, value))
+		}
+	}
+
+	key := requestKey{key: strings.ToUpper(method) + " " + path}
+	if len(parameters) > 0 {
+		key.key += "?" + strings.Join(parameters, "&")
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		return key
+	}
+	if field, items, ok := bulkItems(body); ok {
+		key.items = items
+		sorted := append([]string(nil), items...)
+		sort.Strings(sorted)
+		key.key += " " + field + "=[" + strings.Join(sorted, ",") + "]"
+		return key
+	}
+	key.key += " " + canonicalJSON(body)
+	return key
+}
+
+//bulkItems returns the field and the normalised items of a bulk lookup or bulk reverse geocoding body
+func bulkItems(body []byte) (string, []string, bool) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil || len(fields) != 1 {
+		return "", nil, false
+	}
+	for field, value := range fields {
+		if field != "postcodes" && field != "geolocations" {
+			return "", nil, false
+		}
+		var raw []json.RawMessage
+		if err := json.Unmarshal(value, &raw); err != nil {
+			return "", nil, false
+		}
+		items := make([]string, len(raw))
+		for i, item := range raw {
+			var code string
+			if field == "postcodes" && json.Unmarshal(item, &code) == nil {
+				items[i] = compact(code)
+				continue
+			}
+			items[i] = canonicalJSON(item)
+		}
+		return field, items, true
+	}
+	return "", nil, false
+}
+
+//reorder returns the response body of a bulk request with its results, recorded in the order of the recorded items,
+//in the order of the requested items. The body is returned as is if it does not hold a result per item.
+func reorder(body []byte, recorded, requested []string) []byte {
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return body
+	}
+	var results []json.RawMessage
+	if err := json.Unmarshal(envelope["result"], &results); err != nil || len(results) != len(recorded) {
+		return body
+	}
+
+	used := make([]bool, len(recorded))
+	ordered := make([]json.RawMessage, len(requested))
+	for i, item := range requested {
+		for j := range recorded {
+			if !used[j] && recorded[j] == item {
+				used[j] = true
+				ordered[i] = results[j]
+				break
+			}
+		}
+	}
+
+	encoded, err := json.Marshal(ordered)
+	if err != nil {
+		return body
+	}
+	envelope["result"] = encoded
+	reordered, err := json.Marshal(envelope)
+	if err != nil {
+		return body
+	}
+	return reordered
+}
+
+//normaliseValue returns the given query parameter value with its numbers formatted alike and its filter attributes
+//sorted
+func normaliseValue(name, value string) string {
+	value = strings.TrimSpace(value)
+	if number, err := strconv.ParseFloat(value, 64); err == nil {
+		return strconv.FormatFloat(number, 'f', -1, 64)
+	}
+	if name == "filter" {
+		attributes := fields(value)
+		sort.Strings(attributes)
+		return strings.Join(attributes, ",")
+	}
+	return value
+}
+
+//canonicalJSON returns the given JSON with its object keys sorted and its numbers formatted alike, or as is if it is
+//not valid JSON
+func canonicalJSON(data []byte) string {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return string(bytes.TrimSpace(data))
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return string(bytes.TrimSpace(data))
+	}
+	return string(encoded)
+}
+
+//fixtureName returns the file name of the fixture of the given key, e.g. get_postcodes_RG11AF_1a2b3c4d5e6f.json
+func fixtureName(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	parts := strings.SplitN(key, " ", 3)
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case r == '/':
+			return '_'
+		}
+		return -1
+	}, strings.ToLower(parts[0])+"/"+strings.SplitN(parts[1], "?", 2)[0])
+	if len(name) > 60 {
+		name = name[:60]
+	}
+	return name + "_" + hex.EncodeToString(sum[:6]) + ".json"
+}
+
+//readBody reads the body of the request and restores it to be sent
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+//response returns a replayed response to the request
+func response(req *http.Request, status int, header http.Header, body []byte) *http.Response {
+	header = header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	if header.Get("Content-Type") == "" {
+		header.Set("Content-Type", "application/json; charset=utf-8")
+	}
+	return &http.Response{
+		Status:        strconv.Itoa(status) + " " + http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
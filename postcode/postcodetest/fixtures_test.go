@@ -0,0 +1,101 @@
+package postcodetest_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/razorcorp/postcode-sdk-go/model"
+	"github.com/razorcorp/postcode-sdk-go/postcode"
+	"github.com/razorcorp/postcode-sdk-go/postcode/postcodetest"
+)
+
+func TestRecordReplay(t *testing.T) {
+	dir := t.TempDir()
+	fake := newServer(t)
+	recorder, err := postcodetest.NewRecorder(dir, fake.Server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	recording := fake.Client(postcode.WithDoer(recorder))
+	if _, err := recording.Lookup("RG1 1AF"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := recording.Lookup("RG1 9ZZ"); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("Lookup() of an unknown postcode error = %v", err)
+	}
+	if _, err := recording.BulkLookup(postcode.Postcodes{Postcodes: []string{"RG1 1AF", "RG1 9ZZ", "SW1A 1AA"}}, nil); err != nil {
+		t.Fatal(err)
+	}
+	limit := int64(2)
+	if _, err := recording.Query("RG1", &limit); err != nil {
+		t.Fatal(err)
+	}
+	recorded := fake.Calls("", "")
+
+	replayer, err := postcodetest.NewReplayer(dir, postcodetest.ReplayOptions{Strict: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := postcode.NewClient(postcode.WithBaseURL("http://replay.invalid"), postcode.WithDoer(replayer),
+		postcode.WithRetryPolicy(postcode.RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}))
+
+	data, lookupErr := client.Lookup("rg11af")
+	if lookupErr != nil || data.Postcode != "RG1 1AF" {
+		t.Errorf("Lookup() = %+v, %v, want RG1 1AF", data, lookupErr)
+	}
+	if _, err := client.Lookup("rg1 9zz"); !errors.Is(err, model.ErrNotFound) || err.Message != "Postcode not found" {
+		t.Errorf("Lookup() of an unknown postcode error = %v, want the recorded 404", err)
+	}
+
+	bulk, lookupErr := client.BulkLookup(postcode.Postcodes{Postcodes: []string{"SW1A1AA", "RG1 1AF", "RG1 9ZZ"}}, nil)
+	if lookupErr != nil || len(bulk) != 3 {
+		t.Fatalf("BulkLookup() = %v, %v", bulk, lookupErr)
+	}
+	for i, want := range []string{"SW1A 1AA", "RG1 1AF", ""} {
+		if bulk[i].Postcode.Postcode != want {
+			t.Errorf("BulkLookup() result %d = %q, want %q in the order requested", i, bulk[i].Postcode.Postcode, want)
+		}
+	}
+
+	limit = 2
+	found, lookupErr := client.Query("RG1", &limit)
+	if lookupErr != nil || len(found) != 2 {
+		t.Errorf("Query() = %d postcodes, %v, want 2", len(found), lookupErr)
+	}
+	if unrecorded := replayer.Unrecorded(); len(unrecorded) != 0 {
+		t.Errorf("Unrecorded() = %v, want none", unrecorded)
+	}
+
+	_, lookupErr = client.Lookup("SW1A 2AA")
+	if !errors.Is(lookupErr, postcodetest.ErrUnrecorded) || !errors.Is(lookupErr, model.ErrRequest) || errors.Is(lookupErr, model.ErrTransport) {
+		t.Errorf("Lookup() of an unrecorded postcode error = %v, want a request error wrapping ErrUnrecorded", lookupErr)
+	}
+	if unrecorded := replayer.Unrecorded(); len(unrecorded) != 1 || unrecorded[0] != "GET postcodes/SW1A2AA" {
+		t.Errorf("Unrecorded() = %v, want a single attempt of GET postcodes/SW1A2AA", unrecorded)
+	}
+	if calls := fake.Calls("", ""); calls != recorded {
+		t.Errorf("Calls() = %d, want %d, the replay must not reach the server", calls, recorded)
+	}
+}
+
+func TestReplayFallback(t *testing.T) {
+	fake := newServer(t)
+	replayer, err := postcodetest.NewReplayer(t.TempDir(), postcodetest.ReplayOptions{Fallback: fake.Server.Client()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, lookupErr := fake.Client(postcode.WithDoer(replayer)).Lookup("RG1 1AF")
+	if lookupErr != nil || data.Postcode != "RG1 1AF" {
+		t.Errorf("Lookup() = %+v, %v, want RG1 1AF from the fallback", data, lookupErr)
+	}
+
+	replayer, err = postcodetest.NewReplayer(t.TempDir(), postcodetest.ReplayOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fake.Client(postcode.WithDoer(replayer)).Lookup("RG1 1AF"); err == nil || err.Status != http.StatusNotFound {
+		t.Errorf("Lookup() without fixture error = %v, want 404", err)
+	}
+}
@@ -0,0 +1,592 @@
+//Package postcodetest provides an in-process fake of the postcodes.io API for testing code using the postcode package
+//without network access
+package postcodetest
+
+import (
+	"encoding/json"
+	"github.com/razorcorp/postcode-sdk-go/model"
+	"github.com/razorcorp/postcode-sdk-go/postcode"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type (
+	//Server is a fake postcodes.io API serving a Dataset from memory on a local httptest.Server. It implements every
+	//endpoint used by the postcode package with the status, result and error envelope of the API, including its null
+	//results, 404 and 400 responses, default limits and radii. Random endpoints are deterministic.
+	//
+	//	fake := postcodetest.NewServer(postcodetest.SampleDataset())
+	//	defer fake.Close()
+	//	client := postcode.NewClient(postcode.WithBaseURL(fake.URL))
+	//
+	//Faults added with Inject make the Server misbehave for selected calls, to test retries, timeouts and error
+	//handling. Server is safe for concurrent use.
+	Server struct {
+		*httptest.Server
+
+		mu         sync.RWMutex
+		postcodes  map[string]model.Postcode
+		outcodes   map[string]model.OutcodeData
+		scottish   map[string]model.ScottishPostcodeData
+		terminated map[string]model.TerminatedPostcode
+		places     map[string]model.Place
+
+		randomMu sync.Mutex
+		random   *rand.Rand
+
+		faultsMu sync.Mutex
+		faults   []Fault
+		calls    map[string]int
+	}
+
+	//failure error response of the API
+	failure struct {
+		status  int
+		message string
+	}
+
+	resultEnvelope struct {
+		Status int         `json:"status"`
+		Result interface{} `json:"result"`
+	}
+
+	errorEnvelope struct {
+		Status int    `json:"status"`
+		Error  string `json:"error"`
+	}
+
+	//bulkResult result of a single postcode or geolocation of a bulk request
+	bulkResult struct {
+		Query  interface{} `json:"query"`
+		Result interface{} `json:"result"`
+	}
+
+	bulkRequest struct {
+		Postcodes    []string        `json:"postcodes"`
+		Geolocations []model.Geocode `json:"geolocations"`
+	}
+)
+
+//NewServer starts and returns a Server serving the given dataset. The caller should call Close when finished, to shut
+//it down.
+func NewServer(dataset Dataset) *Server {
+	s := &Server{
+		postcodes:  make(map[string]model.Postcode),
+		outcodes:   make(map[string]model.OutcodeData),
+		scottish:   make(map[string]model.ScottishPostcodeData),
+		terminated: make(map[string]model.TerminatedPostcode),
+		places:     make(map[string]model.Place),
+		random:     rand.New(rand.NewSource(1)),
+		calls:      make(map[string]int),
+	}
+	s.Seed(dataset)
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+//Seed adds the records of the given dataset to the Server, replacing the records of the same postcodes, outcodes and
+//places. Postcodes are stored in their canonical form, with their outward and inward codes filled in.
+func (s *Server) Seed(dataset Dataset) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, p := range dataset.Postcodes {
+		if parts, err := postcode.Parse(p.Postcode); err == nil {
+			p.Postcode = parts.String()
+			if p.OutCode == "" {
+				p.OutCode, p.InCode = parts.Outcode, parts.Incode
+			}
+		}
+		s.postcodes[compact(p.Postcode)] = p
+	}
+	for _, o := range dataset.Outcodes {
+		o.Outcode = compact(o.Outcode)
+		s.outcodes[o.Outcode] = o
+	}
+	for _, p := range dataset.Scottish {
+		p.Postcode = canonical(p.Postcode)
+		s.scottish[compact(p.Postcode)] = p
+	}
+	for _, p := range dataset.Terminated {
+		p.Postcode = canonical(p.Postcode)
+		s.terminated[compact(p.Postcode)] = p
+	}
+	for _, p := range dataset.Places {
+		s.places[p.Code] = p
+	}
+}
+
+//Client returns a postcode.Client configured with the given options and targeting the Server
+func (s *Server) Client(options ...postcode.Option) *postcode.Client {
+	return postcode.NewClient(append([]postcode.Option{
+		postcode.WithBaseURL(s.URL),
+		postcode.WithHTTPClient(s.Server.Client()),
+	}, options...)...)
+}
+
+//ServeHTTP answers the given API request from the dataset, unless an injected fault matches the request
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if fault := s.fault(r); fault != nil {
+		s.inject(w, r, *fault)
+		return
+	}
+	status, body := s.respond(r)
+	write(w, status, body)
+}
+
+//respond returns the status and the encoded body of the response to the given request
+func (s *Server) respond(r *http.Request) (int, []byte) {
+	s.mu.RLock()
+	result, fail := s.handle(r)
+	s.mu.RUnlock()
+
+	if fail != nil {
+		return fail.status, encode(errorEnvelope{Status: fail.status, Error: fail.message})
+	}
+	return http.StatusOK, encode(resultEnvelope{Status: http.StatusOK, Result: result})
+}
+
+//handle routes the given request to its endpoint
+func (s *Server) handle(r *http.Request) (interface{}, *failure) {
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	query := r.URL.Query()
+
+	if r.Method == http.MethodPost {
+		if len(segments) == 1 && segments[0] == "postcodes" {
+			return s.bulk(r, fields(query.Get("filter")))
+		}
+		return nil, notFound("Resource not found")
+	}
+	if r.Method != http.MethodGet {
+		return nil, notFound("Resource not found")
+	}
+
+	switch {
+	case len(segments) == 1 && segments[0] == "postcodes":
+		if q := strings.TrimSpace(query.Get("q")); q != "" {
+			return s.query(q, intParam(query, "limit", 10, 100)), nil
+		}
+		if query.Has("lon") || query.Has("lat") {
+			return s.reverseGeocoding(query)
+		}
+		return nil, badRequest("No postcode query submitted. Remember to include query parameter")
+	case len(segments) == 2 && segments[0] == "postcodes":
+		return s.lookup(segments[1])
+	case len(segments) == 3 && segments[0] == "postcodes" && segments[2] == "validate":
+		_, ok := s.postcodes[compact(segments[1])]
+		return ok, nil
+	case len(segments) == 3 && segments[0] == "postcodes" && segments[2] == "nearest":
+		return s.nearestPostcode(segments[1], query)
+	case len(segments) == 3 && segments[0] == "postcodes" && segments[2] == "autocomplete":
+		return s.autocomplete(segments[1], intParam(query, "limit", 10, 100)), nil
+	case len(segments) == 1 && segments[0] == "outcodes":
+		return s.outcodeReverseGeocoding(query)
+	case len(segments) == 2 && segments[0] == "outcodes":
+		outcode, ok := s.outcodeData()[compact(segments[1])]
+		if !ok {
+			return nil, notFound("Outcode not found")
+		}
+		return outcode, nil
+	case len(segments) == 3 && segments[0] == "outcodes" && segments[2] == "nearest":
+		return s.nearestOutcode(segments[1], query)
+	case len(segments) == 3 && segments[0] == "scotland" && segments[1] == "postcodes":
+		if _, err := postcode.Parse(segments[2]); err != nil {
+			return nil, notFound("Invalid postcode")
+		}
+		data, ok := s.scottish[compact(segments[2])]
+		if !ok {
+			return nil, notFound("Postcode not found")
+		}
+		return data, nil
+	case len(segments) == 2 && segments[0] == "terminated_postcodes":
+		if _, err := postcode.Parse(segments[1]); err != nil {
+			return nil, notFound("Invalid postcode")
+		}
+		data, ok := s.terminated[compact(segments[1])]
+		if !ok {
+			return nil, notFound("Terminated postcode not found")
+		}
+		return data, nil
+	case len(segments) == 1 && segments[0] == "places":
+		q := strings.TrimSpace(query.Get("q"))
+		if q == "" {
+			return nil, badRequest("No query submitted. Remember to include query parameter")
+		}
+		return s.placeQuery(q, intParam(query, "limit", 10, 100)), nil
+	case len(segments) == 2 && segments[0] == "places":
+		place, ok := s.places[segments[1]]
+		if !ok {
+			return nil, notFound("Place not found")
+		}
+		return place, nil
+	case len(segments) == 2 && segments[0] == "random" && segments[1] == "postcodes":
+		return s.randomPostcode(query.Get("outcode")), nil
+	case len(segments) == 2 && segments[0] == "random" && segments[1] == "places":
+		return s.randomPlace(), nil
+	}
+	return nil, notFound("Resource not found")
+}
+
+func (s *Server) lookup(code string) (interface{}, *failure) {
+	if _, err := postcode.Parse(code); err != nil {
+		return nil, notFound("Invalid postcode")
+	}
+	data, ok := s.postcodes[compact(code)]
+	if !ok {
+		return nil, notFound("Postcode not found")
+	}
+	return data, nil
+}
+
+//bulk answers a bulk lookup or bulk reverse geocoding request, with the result attributes limited to the given
+//filters
+func (s *Server) bulk(r *http.Request, filters []string) (interface{}, *failure) {
+	var request bulkRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, badRequest("Invalid JSON submitted. You need to submit a JSON object with an array of postcodes or geolocation objects")
+	}
+
+	switch {
+	case request.Postcodes != nil:
+		if len(request.Postcodes) > 100 {
+			return nil, badRequest("Too many postcodes submitted. Up to 100 postcodes can be bulk requested at a time")
+		}
+		results := make([]bulkResult, len(request.Postcodes))
+		for i, code := range request.Postcodes {
+			results[i].Query = code
+			if data, ok := s.postcodes[compact(code)]; ok {
+				results[i].Result = project(data, filters)
+			}
+		}
+		return results, nil
+	case request.Geolocations != nil:
+		if len(request.Geolocations) > 100 {
+			return nil, badRequest("Too many locations submitted. Up to 100 locations can be bulk requested at a time")
+		}
+		results := make([]bulkResult, len(request.Geolocations))
+		for i, geocode := range request.Geolocations {
+			results[i].Query = geocode
+			limit, radius := clamp(geocode.Limit, 10, 100), clamp(geocode.Radius, 100, 2000)
+			if geocode.WideSearch {
+				limit, radius = clamp(limit, 10, 10), 20000
+			}
+			var postcodes []interface{}
+			for _, data := range s.nearestPostcodes(geocode.Latitude, geocode.Longitude, float64(radius), int(limit)) {
+				postcodes = append(postcodes, project(data, filters))
+			}
+			if postcodes != nil {
+				results[i].Result = postcodes
+			}
+		}
+		return results, nil
+	}
+	return nil, badRequest("Invalid JSON submitted. You need to submit a JSON object with an array of postcodes or geolocation objects")
+}
+
+func (s *Server) reverseGeocoding(query url.Values) (interface{}, *failure) {
+	latitude, longitude, ok := coordinates(query)
+	if !ok {
+		return nil, badRequest("Invalid longitude/latitude submitted")
+	}
+	limit, radius := intParam(query, "limit", 10, 100), floatParam(query, "radius", 100, 2000)
+	if query.Get("widesearch") == "true" {
+		limit, radius = int(clamp(int64(limit), 10, 10)), 20000
+	}
+	return s.nearestPostcodes(latitude, longitude, radius, limit), nil
+}
+
+func (s *Server) nearestPostcode(code string, query url.Values) (interface{}, *failure) {
+	centre, fail := s.lookup(code)
+	if fail != nil {
+		return nil, fail
+	}
+	data := centre.(model.Postcode)
+	return s.nearestPostcodes(data.Latitude, data.Longitude, floatParam(query, "radius", 100, 2000), intParam(query, "limit", 10, 100)), nil
+}
+
+//nearestPostcodes returns up to limit postcodes within the given radius in metres of the coordinates, nearest first
+func (s *Server) nearestPostcodes(latitude, longitude, radius float64, limit int) []model.Postcode {
+	var data []model.Postcode
+	for _, p := range s.postcodes {
+		p.Distance = haversine(latitude, longitude, p.Latitude, p.Longitude)
+		if p.Distance <= radius {
+			data = append(data, p)
+		}
+	}
+	sort.Slice(data, func(i, j int) bool {
+		if data[i].Distance != data[j].Distance {
+			return data[i].Distance < data[j].Distance
+		}
+		return data[i].Postcode < data[j].Postcode
+	})
+	if len(data) > limit {
+		data = data[:limit]
+	}
+	return data
+}
+
+//query returns up to limit postcodes starting with the given prefix, in order
+func (s *Server) query(prefix string, limit int) []model.Postcode {
+	var data []model.Postcode
+	for _, p := range s.sortedPostcodes() {
+		if len(data) < limit && strings.HasPrefix(compact(p.Postcode), compact(prefix)) {
+			data = append(data, p)
+		}
+	}
+	return data
+}
+
+func (s *Server) autocomplete(prefix string, limit int) []string {
+	var data []string
+	for _, p := range s.query(prefix, limit) {
+		data = append(data, p.Postcode)
+	}
+	return data
+}
+
+func (s *Server) outcodeReverseGeocoding(query url.Values) (interface{}, *failure) {
+	latitude, longitude, ok := coordinates(query)
+	if !ok {
+		return nil, badRequest("Invalid longitude/latitude submitted")
+	}
+	return s.nearestOutcodes(latitude, longitude, floatParam(query, "radius", 5000, 25000), intParam(query, "limit", 10, 100)), nil
+}
+
+func (s *Server) nearestOutcode(code string, query url.Values) (interface{}, *failure) {
+	centre, ok := s.outcodeData()[compact(code)]
+	if !ok {
+		return nil, notFound("Outcode not found")
+	}
+	return s.nearestOutcodes(centre.Latitude, centre.Longitude, floatParam(query, "radius", 5000, 25000), intParam(query, "limit", 10, 100)), nil
+}
+
+//nearestOutcodes returns up to limit outcodes within the given radius in metres of the coordinates, nearest first
+func (s *Server) nearestOutcodes(latitude, longitude, radius float64, limit int) []model.OutcodeData {
+	var data []model.OutcodeData
+	distances := make(map[string]float64)
+	for code, o := range s.outcodeData() {
+		distances[code] = haversine(latitude, longitude, o.Latitude, o.Longitude)
+		if distances[code] <= radius {
+			data = append(data, o)
+		}
+	}
+	sort.Slice(data, func(i, j int) bool {
+		if distances[data[i].Outcode] != distances[data[j].Outcode] {
+			return distances[data[i].Outcode] < distances[data[j].Outcode]
+		}
+		return data[i].Outcode < data[j].Outcode
+	})
+	if len(data) > limit {
+		data = data[:limit]
+	}
+	return data
+}
+
+//outcodeData returns the outcodes of the dataset keyed by outcode, deriving those without a record from their
+//postcodes
+func (s *Server) outcodeData() map[string]model.OutcodeData {
+	data := make(map[string]model.OutcodeData, len(s.outcodes))
+	counts := make(map[string]int)
+	for _, p := range s.sortedPostcodes() {
+		if p.OutCode == "" {
+			continue
+		}
+		o := data[p.OutCode]
+		o.Outcode = p.OutCode
+		o.Latitude += p.Latitude
+		o.Longitude += p.Longitude
+		o.Eastings += p.Eastings
+		o.Northings += p.Northings
+		o.AdminDistrict = appendDistinct(o.AdminDistrict, p.AdminDistrict)
+		o.AdminCounty = appendDistinct(o.AdminCounty, p.AdminCounty)
+		o.AdminWard = appendDistinct(o.AdminWard, p.AdminWard)
+		o.Parish = appendDistinct(o.Parish, p.Parish)
+		o.Country = appendDistinct(o.Country, p.Country)
+		data[p.OutCode] = o
+		counts[p.OutCode]++
+	}
+	for code, o := range data {
+		n := counts[code]
+		o.Latitude /= float64(n)
+		o.Longitude /= float64(n)
+		o.Eastings /= int64(n)
+		o.Northings /= int64(n)
+		data[code] = o
+	}
+	for code, o := range s.outcodes {
+		data[code] = o
+	}
+	return data
+}
+
+//placeQuery returns up to limit places whose names start with the given query, in order
+func (s *Server) placeQuery(q string, limit int) []model.Place {
+	q = strings.ToLower(q)
+	var data []model.Place
+	for _, p := range s.sortedPlaces() {
+		if len(data) < limit && (strings.HasPrefix(strings.ToLower(p.Name1), q) || strings.HasPrefix(strings.ToLower(p.Name2), q)) {
+			data = append(data, p)
+		}
+	}
+	return data
+}
+
+//randomPostcode returns a random postcode, of the given outcode if any, or nil if there is none
+func (s *Server) randomPostcode(outcode string) interface{} {
+	var candidates []model.Postcode
+	for _, p := range s.sortedPostcodes() {
+		if outcode == "" || p.OutCode == compact(outcode) {
+			candidates = append(candidates, p)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	return candidates[s.intn(len(candidates))]
+}
+
+//randomPlace returns a random place, or nil if there is none
+func (s *Server) randomPlace() interface{} {
+	places := s.sortedPlaces()
+	if len(places) == 0 {
+		return nil
+	}
+	return places[s.intn(len(places))]
+}
+
+func (s *Server) intn(n int) int {
+	s.randomMu.Lock()
+	defer s.randomMu.Unlock()
+	return s.random.Intn(n)
+}
+
+func (s *Server) sortedPostcodes() []model.Postcode {
+	data := make([]model.Postcode, 0, len(s.postcodes))
+	for _, p := range s.postcodes {
+		data = append(data, p)
+	}
+	sort.Slice(data, func(i, j int) bool {
+		return data[i].Postcode < data[j].Postcode
+	})
+	return data
+}
+
+func (s *Server) sortedPlaces() []model.Place {
+	data := make([]model.Place, 0, len(s.places))
+	for _, p := range s.places {
+		data = append(data, p)
+	}
+	sort.Slice(data, func(i, j int) bool {
+		if data[i].Name1 != data[j].Name1 {
+			return data[i].Name1 < data[j].Name1
+		}
+		return data[i].Code < data[j].Code
+	})
+	return data
+}
+
+func notFound(message string) *failure {
+	return &failure{status: http.StatusNotFound, message: message}
+}
+
+func badRequest(message string) *failure {
+	return &failure{status: http.StatusBadRequest, message: message}
+}
+
+func write(w http.ResponseWriter, status int, body []byte) {
+	writeHeader(w, status, len(body))
+	w.Write(body)
+}
+
+//writeHeader writes the response header announcing a JSON body of the given length
+func writeHeader(w http.ResponseWriter, status, length int) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Content-Length", strconv.Itoa(length))
+	w.WriteHeader(status)
+}
+
+func encode(body interface{}) []byte {
+	encoded, _ := json.Marshal(body)
+	return append(encoded, '\n')
+}
+
+//project returns the given record limited to the given attributes, or the record itself without filters
+func project(data interface{}, filters []string) interface{} {
+	if len(filters) == 0 {
+		return data
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return data
+	}
+	var attributes map[string]interface{}
+	if err := json.Unmarshal(encoded, &attributes); err != nil {
+		return data
+	}
+	projected := make(map[string]interface{})
+	for _, filter := range filters {
+		if value, ok := attributes[filter]; ok {
+			projected[filter] = value
+		}
+	}
+	return projected
+}
+
+//fields splits the given comma separated filter
+func fields(filter string) []string {
+	var data []string
+	for _, field := range strings.Split(filter, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			data = append(data, field)
+		}
+	}
+	return data
+}
+
+func coordinates(query url.Values) (latitude, longitude float64, ok bool) {
+	latitude, latErr := strconv.ParseFloat(query.Get("lat"), 64)
+	longitude, lonErr := strconv.ParseFloat(query.Get("lon"), 64)
+	if latErr != nil || lonErr != nil || latitude < -90 || latitude > 90 || longitude < -180 || longitude > 180 {
+		return 0, 0, false
+	}
+	return latitude, longitude, true
+}
+
+//intParam returns the positive integer query parameter of the given key, the default if missing or invalid, capped at
+//the maximum
+func intParam(query url.Values, key string, value, max int64) int {
+	parsed, err := strconv.ParseInt(query.Get(key), 10, 64)
+	if err != nil {
+		parsed = value
+	}
+	return int(clamp(parsed, value, max))
+}
+
+//floatParam is like intParam for numeric parameters accepting decimals
+func floatParam(query url.Values, key string, value, max float64) float64 {
+	parsed, err := strconv.ParseFloat(query.Get(key), 64)
+	if err != nil || parsed <= 0 {
+		return value
+	}
+	if parsed > max {
+		return max
+	}
+	return parsed
+}
+
+//clamp returns the given value, the default if not positive, capped at the maximum
+func clamp(value, defaultValue, max int64) int64 {
+	if value <= 0 {
+		value = defaultValue
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
@@ -0,0 +1,210 @@
+package postcodetest_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/razorcorp/postcode-sdk-go/model"
+	"github.com/razorcorp/postcode-sdk-go/postcode"
+	"github.com/razorcorp/postcode-sdk-go/postcode/postcodetest"
+)
+
+func newServer(t *testing.T) *postcodetest.Server {
+	t.Helper()
+	fake := postcodetest.NewServer(postcodetest.SampleDataset())
+	t.Cleanup(fake.Close)
+	return fake
+}
+
+func int64p(n int64) *int64 {
+	return &n
+}
+
+func TestServerPostcodes(t *testing.T) {
+	client := newServer(t).Client()
+
+	data, err := client.Lookup("rg11af")
+	if err != nil || data.Postcode != "RG1 1AF" || data.AdminDistrict != "Reading" || data.OutCode != "RG1" {
+		t.Errorf("Lookup() = %+v, %v", data, err)
+	}
+	if _, err := client.Lookup("RG1 9ZZ"); !errors.Is(err, model.ErrNotFound) || err.Message != "Postcode not found" {
+		t.Errorf("Lookup() of an unknown postcode error = %v, want Postcode not found", err)
+	}
+
+	valid, err := client.Validation("SW1A 1AA")
+	if err != nil || !valid {
+		t.Errorf("Validation() = %v, %v, want true", valid, err)
+	}
+	if valid, err = client.Validation("RG1 9ZZ"); err != nil || valid {
+		t.Errorf("Validation() of an unknown postcode = %v, %v, want false", valid, err)
+	}
+
+	found, err := client.Query("RG1", nil)
+	if err != nil || len(found) != 4 {
+		t.Errorf("Query() = %d postcodes, %v, want the 4 postcodes starting RG1", len(found), err)
+	}
+	if found, err = client.Query("RG1", int64p(1)); err != nil || len(found) != 1 {
+		t.Errorf("Query() with limit = %d postcodes, %v, want 1", len(found), err)
+	}
+
+	completed, err := client.Autocomplete("SW1A", nil)
+	if err != nil || len(completed) != 2 || completed[0] != "SW1A 1AA" {
+		t.Errorf("Autocomplete() = %v, %v, want SW1A 1AA and SW1A 2AA", completed, err)
+	}
+
+	nearest, err := client.NearestPostcode("RG1 1AF", nil, int64p(500))
+	if err != nil || len(nearest) != 3 || nearest[0].Postcode != "RG1 1AF" {
+		t.Errorf("NearestPostcode() = %v, %v, want the 3 Reading postcodes nearest first", nearest, err)
+	}
+
+	outcode := "RG1"
+	random, err := client.RandomPostcode(&outcode)
+	if err != nil || random.OutCode != "RG1" {
+		t.Errorf("RandomPostcode() = %+v, %v, want a postcode in RG1", random, err)
+	}
+}
+
+func TestServerBulkLookup(t *testing.T) {
+	client := newServer(t).Client()
+
+	data, err := client.BulkLookup(postcode.Postcodes{Postcodes: []string{"RG1 1AF", "RG1 9ZZ", "SW1A 2AA"}}, []string{"postcode", "admin_district"})
+	if err != nil || len(data) != 3 {
+		t.Fatalf("BulkLookup() = %v, %v", data, err)
+	}
+	if data[0].Postcode.Postcode != "RG1 1AF" || data[0].Postcode.AdminDistrict != "Reading" || data[0].Postcode.Country != "" {
+		t.Errorf("BulkLookup() result = %+v, want postcode and admin_district only", data[0].Postcode)
+	}
+	if data[1].Query != "RG1 9ZZ" || data[1].Postcode.Postcode != "" {
+		t.Errorf("BulkLookup() result of an unknown postcode = %+v, want null", data[1])
+	}
+	if data[2].Postcode.Postcode != "SW1A 2AA" {
+		t.Errorf("BulkLookup() result = %+v, want SW1A 2AA", data[2])
+	}
+}
+
+func TestServerReverseGeocoding(t *testing.T) {
+	client := newServer(t).Client()
+
+	found, err := client.ReverseGeocoding(postcode.Geocode{Latitude: 51.456813, Longitude: -0.971396})
+	if err != nil || len(found) == 0 || found[0].Postcode != "RG1 1AF" {
+		t.Errorf("ReverseGeocoding() = %v, %v, want RG1 1AF first", found, err)
+	}
+
+	bulk, err := client.BulkReverseGeocoding(postcode.Geocodes{Geolocations: []postcode.Geocode{
+		{Latitude: 51.501009, Longitude: -0.141588},
+		{Latitude: 50, Longitude: -5},
+	}}, nil)
+	if err != nil || len(bulk) != 2 {
+		t.Fatalf("BulkReverseGeocoding() = %v, %v", bulk, err)
+	}
+	if len(bulk[0].Postcode) == 0 || bulk[0].Postcode[0].Postcode != "SW1A 1AA" {
+		t.Errorf("BulkReverseGeocoding() result = %+v, want SW1A 1AA first", bulk[0])
+	}
+	if len(bulk[1].Postcode) != 0 {
+		t.Errorf("BulkReverseGeocoding() result far from any postcode = %+v, want null", bulk[1])
+	}
+}
+
+func TestServerOutcodes(t *testing.T) {
+	client := newServer(t).Client()
+
+	data, err := client.OutcodeLookup("rg1")
+	if err != nil || data.Outcode != "RG1" || len(data.AdminDistrict) != 1 || data.AdminDistrict[0] != "Reading" {
+		t.Errorf("OutcodeLookup() = %+v, %v", data, err)
+	}
+	if _, err := client.OutcodeLookup("RG99"); !errors.Is(err, model.ErrNotFound) || err.Message != "Outcode not found" {
+		t.Errorf("OutcodeLookup() of an unknown outcode error = %v, want Outcode not found", err)
+	}
+
+	found, err := client.OutcodeReverseGeocoding(postcode.Geocode{Latitude: 51.456, Longitude: -0.971})
+	if err != nil || len(found) == 0 || found[0].Outcode != "RG1" {
+		t.Errorf("OutcodeReverseGeocoding() = %v, %v, want RG1 first", found, err)
+	}
+
+	nearest, err := client.NearestOutcode("RG1", nil, int64p(25000))
+	if err != nil || len(nearest) != 2 || nearest[0].Outcode != "RG1" || nearest[1].Outcode != "RG12" {
+		t.Errorf("NearestOutcode() = %v, %v, want RG1 and RG12", nearest, err)
+	}
+}
+
+func TestServerScottishAndTerminated(t *testing.T) {
+	client := newServer(t).Client()
+
+	scottish, err := client.ScottishPostcodeLookup("EH1 1YZ")
+	if err != nil || scottish.ScottishParliamentaryConstituency != "Edinburgh Central" {
+		t.Errorf("ScottishPostcodeLookup() = %+v, %v", scottish, err)
+	}
+	if _, err := client.ScottishPostcodeLookup("RG1 1AF"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("ScottishPostcodeLookup() of an English postcode error = %v, want not found", err)
+	}
+
+	terminated, err := client.TerminatedPostcodeLookup("RG1 1AB")
+	if err != nil || terminated.YearTerminated != 1999 || terminated.MonthTerminated != 6 {
+		t.Errorf("TerminatedPostcodeLookup() = %+v, %v", terminated, err)
+	}
+	if _, err := client.TerminatedPostcodeLookup("RG1 1AF"); !errors.Is(err, model.ErrNotFound) || err.Message != "Terminated postcode not found" {
+		t.Errorf("TerminatedPostcodeLookup() of a live postcode error = %v, want Terminated postcode not found", err)
+	}
+}
+
+func TestServerPlaces(t *testing.T) {
+	client := newServer(t).Client()
+
+	place, err := client.PlaceLookup("osgb4000000074564391")
+	if err != nil || place.Name1 != "Reading" {
+		t.Errorf("PlaceLookup() = %+v, %v", place, err)
+	}
+	if _, err := client.PlaceLookup("osgb4000000000000000"); !errors.Is(err, model.ErrNotFound) || err.Message != "Place not found" {
+		t.Errorf("PlaceLookup() of an unknown place error = %v, want Place not found", err)
+	}
+
+	places, err := client.PlaceQuery("read", nil)
+	if err != nil || len(places) != 1 || places[0].Code != "osgb4000000074564391" {
+		t.Errorf("PlaceQuery() = %v, %v, want Reading", places, err)
+	}
+
+	random, err := client.RandomPlace()
+	if err != nil || random.Code != "osgb4000000074564391" {
+		t.Errorf("RandomPlace() = %+v, %v, want Reading", random, err)
+	}
+}
+
+func TestServerErrorEnvelopes(t *testing.T) {
+	fake := newServer(t)
+	tests := []struct {
+		method  string
+		path    string
+		status  int
+		message string
+	}{
+		{method: http.MethodGet, path: "/postcodes/NOTAPOSTCODE", status: http.StatusNotFound, message: "Invalid postcode"},
+		{method: http.MethodGet, path: "/terminated_postcodes/NOTAPOSTCODE", status: http.StatusNotFound, message: "Invalid postcode"},
+		{method: http.MethodGet, path: "/postcodes", status: http.StatusBadRequest, message: "No postcode query submitted. Remember to include query parameter"},
+		{method: http.MethodGet, path: "/places", status: http.StatusBadRequest, message: "No query submitted. Remember to include query parameter"},
+		{method: http.MethodGet, path: "/unknown", status: http.StatusNotFound, message: "Resource not found"},
+		{method: http.MethodDelete, path: "/postcodes/RG11AF", status: http.StatusNotFound, message: "Resource not found"},
+	}
+	for _, test := range tests {
+		t.Run(test.method+" "+test.path, func(t *testing.T) {
+			request, err := http.NewRequest(test.method, fake.URL+test.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			response, err := fake.Server.Client().Do(request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer response.Body.Close()
+
+			var envelope model.ResponseError
+			if err := json.NewDecoder(response.Body).Decode(&envelope); err != nil {
+				t.Fatal(err)
+			}
+			if response.StatusCode != test.status || envelope.Status != test.status || envelope.Message != test.message {
+				t.Errorf("response = %d %+v, want %d %q", response.StatusCode, envelope, test.status, test.message)
+			}
+		})
+	}
+}
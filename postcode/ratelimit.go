@@ -0,0 +1,125 @@
+package postcode
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type (
+	//RateLimiter is a token bucket rate limiter shared by all concurrent calls of the clients using it.
+	//Calls exceeding the rate block until a token is available or their context is done.
+	RateLimiter struct {
+		mu     sync.Mutex
+		rate   float64
+		burst  float64
+		tokens float64
+		last   time.Time
+		stats  RateLimiterStats
+	}
+
+	//RateLimiterStats reports how much the RateLimiter throttled the calls
+	RateLimiterStats struct {
+		//Requests number of calls that passed the limiter
+		Requests int64
+
+		//Throttled number of calls that had to wait for a token
+		Throttled int64
+
+		//TotalWait sum of the time waited by all calls
+		TotalWait time.Duration
+
+		//LastWait time waited by the most recent call
+		LastWait time.Duration
+	}
+)
+
+//NewRateLimiter returns a RateLimiter allowing requestsPerSecond calls on average and bursts of up to burst calls
+func NewRateLimiter(requestsPerSecond float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:   requestsPerSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+//WithRateLimit limits the requests made by the Client to requestsPerSecond with bursts of up to burst requests
+func WithRateLimit(requestsPerSecond float64, burst int) Option {
+	return WithRateLimiter(NewRateLimiter(requestsPerSecond, burst))
+}
+
+//WithRateLimiter limits the requests made by the Client with the given RateLimiter, which may be shared with
+//other clients
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(c *Client) {
+		c.limiter = limiter
+	}
+}
+
+//Wait blocks until the call is allowed to proceed and returns how long it waited. Returns the context error if
+//the context is done before then.
+func (l *RateLimiter) Wait(ctx context.Context) (time.Duration, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	delay := l.reserve()
+	if delay > 0 && !sleep(ctx, delay) {
+		l.cancel()
+		return 0, ctx.Err()
+	}
+
+	l.mu.Lock()
+	l.stats.Requests++
+	l.stats.LastWait = delay
+	if delay > 0 {
+		l.stats.Throttled++
+		l.stats.TotalWait += delay
+	}
+	l.mu.Unlock()
+
+	return delay, nil
+}
+
+//Stats returns the throttling statistics of the limiter
+func (l *RateLimiter) Stats() RateLimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.stats
+}
+
+//reserve takes a token from the bucket and returns the delay until the token becomes available
+func (l *RateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.rate <= 0 {
+		return 0
+	}
+
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+//cancel returns the token of an abandoned reservation to the bucket
+func (l *RateLimiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.rate > 0 {
+		l.tokens++
+	}
+}
@@ -0,0 +1,146 @@
+package postcode
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/razorcorp/postcode-sdk-go/model"
+)
+
+func TestRateLimiterBurst(t *testing.T) {
+	limiter := NewRateLimiter(50, 3)
+
+	for i := 0; i < 3; i++ {
+		if wait, err := limiter.Wait(context.Background()); err != nil || wait != 0 {
+			t.Fatalf("Wait() %d within the burst = %v, %v, want no wait", i, wait, err)
+		}
+	}
+	wait, err := limiter.Wait(context.Background())
+	if err != nil || wait <= 0 || wait > 20*time.Millisecond {
+		t.Fatalf("Wait() beyond the burst = %v, %v, want up to 20ms", wait, err)
+	}
+
+	stats := limiter.Stats()
+	want := RateLimiterStats{Requests: 4, Throttled: 1, TotalWait: wait, LastWait: wait}
+	if stats != want {
+		t.Errorf("Stats() = %+v, want %+v", stats, want)
+	}
+}
+
+func TestRateLimiterRefill(t *testing.T) {
+	limiter := NewRateLimiter(100, 2)
+	for i := 0; i < 2; i++ {
+		limiter.Wait(context.Background())
+	}
+
+	// refills more than the burst, which caps the calls allowed without waiting
+	time.Sleep(50 * time.Millisecond)
+	for i := 0; i < 2; i++ {
+		if wait, err := limiter.Wait(context.Background()); err != nil || wait != 0 {
+			t.Fatalf("Wait() %d after the refill = %v, %v, want no wait", i, wait, err)
+		}
+	}
+	if wait, _ := limiter.Wait(context.Background()); wait == 0 {
+		t.Errorf("Wait() beyond the refilled burst = 0, want a wait")
+	}
+
+	stats := limiter.Stats()
+	if stats.Requests != 5 || stats.Throttled != 1 || stats.TotalWait != stats.LastWait {
+		t.Errorf("Stats() = %+v, want 5 requests with the last one throttled", stats)
+	}
+}
+
+func TestRateLimiterSpacesConcurrentCalls(t *testing.T) {
+	limiter := NewRateLimiter(100, 1)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := limiter.Wait(context.Background()); err != nil {
+				t.Errorf("Wait() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Errorf("5 calls at 100/s with a burst of 1 took %v, want at least 40ms", elapsed)
+	}
+	if stats := limiter.Stats(); stats.Requests != 5 || stats.Throttled != 4 {
+		t.Errorf("Stats() = %+v, want 5 requests with 4 throttled", stats)
+	}
+}
+
+func TestRateLimiterWaitCancelled(t *testing.T) {
+	limiter := NewRateLimiter(1, 1)
+	limiter.Wait(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := limiter.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait() error = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Wait() returned after %v, want it to end with the context", elapsed)
+	}
+
+	cancelled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	if _, err := limiter.Wait(cancelled); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait() with a cancelled context error = %v, want canceled", err)
+	}
+
+	limiter.mu.Lock()
+	tokens := limiter.tokens
+	limiter.mu.Unlock()
+	if tokens < 0 {
+		t.Errorf("tokens = %v, want the token of the abandoned wait returned", tokens)
+	}
+	if stats := limiter.Stats(); stats != (RateLimiterStats{Requests: 1}) {
+		t.Errorf("Stats() = %+v, want the abandoned waits left out", stats)
+	}
+}
+
+func TestRateLimiterSharedByClients(t *testing.T) {
+	server, calls := failingServer(t, 0, nil, lookupBody)
+	limiter := NewRateLimiter(20, 2)
+	first := NewClient(WithBaseURL(server.URL), WithRateLimiter(limiter))
+	second := NewClient(WithBaseURL(server.URL), WithRateLimiter(limiter))
+
+	for _, client := range []*Client{first, second, first, second} {
+		if _, err := client.Lookup("RG1 1AF"); err != nil {
+			t.Fatalf("Lookup() error = %v", err)
+		}
+	}
+
+	if got := atomic.LoadInt32(calls); got != 4 {
+		t.Errorf("calls = %d, want 4", got)
+	}
+	stats := limiter.Stats()
+	if stats.Requests != 4 || stats.Throttled != 2 || stats.TotalWait < 50*time.Millisecond {
+		t.Errorf("Stats() = %+v, want 4 requests with the 2 beyond the shared burst throttled", stats)
+	}
+}
+
+func TestRateLimitCancelledRequest(t *testing.T) {
+	server, calls := failingServer(t, 0, nil, lookupBody)
+	client := NewClient(WithBaseURL(server.URL), WithRateLimit(1, 1))
+	client.Lookup("RG1 1AF")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.LookupContext(ctx, "RG1 1AF"); !errors.Is(err, model.ErrTransport) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("LookupContext() error = %v, want a transport error ending with the context", err)
+	}
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("calls = %d, want the throttled request not sent", got)
+	}
+}
@@ -0,0 +1,134 @@
+package postcode
+
+import (
+	"context"
+	"errors"
+	"github.com/razorcorp/postcode-sdk-go/model"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+type (
+	//RetryPolicy configures how failed requests are retried.
+	//
+	//Only idempotent GET requests are retried unless RetryPost is enabled, which opts in the bulk POST endpoints.
+	RetryPolicy struct {
+		//MaxAttempts total number of attempts including the first one. Values less than 2 disable retries
+		MaxAttempts int
+
+		//MinBackoff delay before the first retry. Doubled on every following retry
+		MinBackoff time.Duration
+
+		//MaxBackoff upper limit of the backoff delay, including the delay requested by Retry-After
+		MaxBackoff time.Duration
+
+		//Statuses HTTP statuses that are retried. Transport failures are always retried
+		Statuses []int
+
+		//RetryPost retry the bulk POST endpoints (BulkLookup and BulkReverseGeocoding) as well
+		RetryPost bool
+	}
+
+	//idempotentKey context key marking a POST request as safe to retry regardless of RetryPost
+	idempotentKey struct{}
+)
+
+//DefaultRetryPolicy retries transport failures, throttled requests and temporary server errors up to 3 attempts
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  100 * time.Millisecond,
+	MaxBackoff:  5 * time.Second,
+	Statuses: []int{
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	},
+}
+
+//WithRetryPolicy enables retrying failed requests according to the given policy
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+//allows reports whether a request with the given method and context may be retried
+func (p RetryPolicy) allows(ctx context.Context, method string) bool {
+	if p.MaxAttempts < 2 {
+		return false
+	}
+	return method == http.MethodGet || (method == http.MethodPost && (p.RetryPost || idempotent(ctx)))
+}
+
+//withIdempotent marks the POST request of the returned context as safe to retry like a GET request, e.g. the bulk
+//lookup serving batched Lookup calls
+func withIdempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+//idempotent reports whether the request of the given context is marked as safe to retry
+func idempotent(ctx context.Context) bool {
+	marked, _ := ctx.Value(idempotentKey{}).(bool)
+	return marked
+}
+
+//retryable reports whether the given request error is worth another attempt
+func (p RetryPolicy) retryable(err *model.ResponseError) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, model.ErrTransport) {
+		return true
+	}
+	for _, status := range p.Statuses {
+		if err.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+//backoff returns the delay before the given retry attempt. Retry-After requested by the API on 429 and 503
+//responses takes precedence over the exponential backoff, up to MaxBackoff so that a call without deadline is not
+//blocked for as long as the API asks.
+func (p RetryPolicy) backoff(attempt int, err *model.ResponseError) time.Duration {
+	if err.RetryAfter > 0 && (err.Status == http.StatusTooManyRequests || err.Status == http.StatusServiceUnavailable) {
+		if p.MaxBackoff > 0 && err.RetryAfter > p.MaxBackoff {
+			return p.MaxBackoff
+		}
+		return err.RetryAfter
+	}
+
+	delay := p.MinBackoff
+	for i := 1; i < attempt && (p.MaxBackoff <= 0 || delay < p.MaxBackoff); i++ {
+		delay *= 2
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	// equal jitter keeps at least half of the delay while spreading concurrent retries
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
+
+//sleep waits for the given delay. Returns false if the context is done before the delay elapsed.
+func sleep(ctx context.Context, delay time.Duration) bool {
+	if delay <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
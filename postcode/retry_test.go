@@ -0,0 +1,196 @@
+package postcode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/razorcorp/postcode-sdk-go/model"
+)
+
+const lookupBody = `{"status":200,"result":{"postcode":"RG1 1AF","outcode":"RG1","incode":"1AF"}}`
+
+const bulkBody = `{"status":200,"result":[{"query":"RG1 1AF","result":{"postcode":"RG1 1AF"}}]}`
+
+//failingServer responds with the given failure to the first failures requests, then with the body
+func failingServer(t *testing.T, failures int32, fail func(w http.ResponseWriter), body string) (*httptest.Server, *int32) {
+	t.Helper()
+	calls := new(int32)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(calls, 1) <= failures {
+			fail(w)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server, calls
+}
+
+//status responds with the given status and error envelope
+func status(code int, retryAfter string) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		if retryAfter != "" {
+			w.Header().Set("Retry-After", retryAfter)
+		}
+		w.WriteHeader(code)
+		fmt.Fprintf(w, `{"status":%d,"error":%q}`, code, http.StatusText(code))
+	}
+}
+
+//hangUp closes the connection without responding
+func hangUp(w http.ResponseWriter) {
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err == nil {
+		conn.Close()
+	}
+}
+
+var testPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  time.Millisecond,
+	MaxBackoff:  10 * time.Millisecond,
+	Statuses:    []int{http.StatusTooManyRequests, http.StatusServiceUnavailable},
+}
+
+func TestRetryStatuses(t *testing.T) {
+	tests := []struct {
+		name  string
+		fail  func(w http.ResponseWriter)
+		calls int32
+		err   error
+	}{
+		{name: "server error", fail: status(http.StatusServiceUnavailable, ""), calls: 3},
+		{name: "rate limited", fail: status(http.StatusTooManyRequests, ""), calls: 3},
+		{name: "transport error", fail: hangUp, calls: 3},
+		{name: "not retried status", fail: status(http.StatusBadGateway, ""), calls: 1, err: model.ErrServer},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, calls := failingServer(t, 2, test.fail, lookupBody)
+			client := NewClient(
+				WithBaseURL(server.URL),
+				WithHTTPClient(&http.Client{Transport: &http.Transport{DisableKeepAlives: true}}),
+				WithRetryPolicy(testPolicy),
+			)
+
+			data, err := client.Lookup("RG1 1AF")
+			if test.err != nil {
+				if !errors.Is(err, test.err) {
+					t.Fatalf("Lookup() error = %v, want %v", err, test.err)
+				}
+			} else if err != nil || data.Postcode != "RG1 1AF" {
+				t.Fatalf("Lookup() = %v, %v, want RG1 1AF", data, err)
+			}
+			if got := atomic.LoadInt32(calls); got != test.calls {
+				t.Errorf("calls = %d, want %d", got, test.calls)
+			}
+		})
+	}
+}
+
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	server, calls := failingServer(t, 10, status(http.StatusServiceUnavailable, ""), lookupBody)
+	client := NewClient(WithBaseURL(server.URL), WithRetryPolicy(testPolicy))
+
+	if _, err := client.Lookup("RG1 1AF"); !errors.Is(err, model.ErrServer) {
+		t.Fatalf("Lookup() error = %v, want %v", err, model.ErrServer)
+	}
+	if got := atomic.LoadInt32(calls); got != 3 {
+		t.Errorf("calls = %d, want 3", got)
+	}
+}
+
+func TestRetryPost(t *testing.T) {
+	postcodes := Postcodes{Postcodes: []string{"RG1 1AF"}}
+
+	server, calls := failingServer(t, 1, status(http.StatusServiceUnavailable, ""), bulkBody)
+	client := NewClient(WithBaseURL(server.URL), WithRetryPolicy(testPolicy))
+	if _, err := client.BulkLookup(postcodes, nil); !errors.Is(err, model.ErrServer) {
+		t.Fatalf("BulkLookup() error = %v, want %v", err, model.ErrServer)
+	}
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("calls without RetryPost = %d, want 1", got)
+	}
+
+	policy := testPolicy
+	policy.RetryPost = true
+	server, calls = failingServer(t, 1, status(http.StatusServiceUnavailable, ""), bulkBody)
+	client = NewClient(WithBaseURL(server.URL), WithRetryPolicy(policy))
+	data, err := client.BulkLookup(postcodes, nil)
+	if err != nil || len(data) != 1 || data[0].Postcode.Postcode != "RG1 1AF" {
+		t.Fatalf("BulkLookup() = %v, %v, want RG1 1AF", data, err)
+	}
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Errorf("calls with RetryPost = %d, want 2", got)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	server, calls := failingServer(t, 1, status(http.StatusTooManyRequests, "1"), lookupBody)
+	policy := testPolicy
+	policy.MaxBackoff = 5 * time.Second
+	client := NewClient(WithBaseURL(server.URL), WithRetryPolicy(policy))
+
+	start := time.Now()
+	if _, err := client.Lookup("RG1 1AF"); err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("Lookup() retried after %v, want at least the Retry-After of 1s", elapsed)
+	}
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Errorf("calls = %d, want 2", got)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	policy := RetryPolicy{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	tests := []struct {
+		name     string
+		attempt  int
+		err      *model.ResponseError
+		min, max time.Duration
+	}{
+		{name: "first retry", attempt: 1, err: &model.ResponseError{Status: 500}, min: 50 * time.Millisecond, max: 100 * time.Millisecond},
+		{name: "doubled", attempt: 3, err: &model.ResponseError{Status: 500}, min: 200 * time.Millisecond, max: 400 * time.Millisecond},
+		{name: "capped", attempt: 10, err: &model.ResponseError{Status: 500}, min: 500 * time.Millisecond, max: time.Second},
+		{name: "retry after", attempt: 1, err: &model.ResponseError{Status: 429, RetryAfter: 700 * time.Millisecond}, min: 700 * time.Millisecond, max: 700 * time.Millisecond},
+		{name: "retry after capped", attempt: 1, err: &model.ResponseError{Status: 503, RetryAfter: time.Hour}, min: time.Second, max: time.Second},
+		{name: "retry after ignored", attempt: 1, err: &model.ResponseError{Status: 500, RetryAfter: time.Hour}, min: 50 * time.Millisecond, max: 100 * time.Millisecond},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := policy.backoff(test.attempt, test.err); got < test.min || got > test.max {
+				t.Errorf("backoff() = %v, want between %v and %v", got, test.min, test.max)
+			}
+		})
+	}
+}
+
+func TestRetryCancelledWhileSleeping(t *testing.T) {
+	server, calls := failingServer(t, 10, status(http.StatusServiceUnavailable, ""), lookupBody)
+	policy := testPolicy
+	policy.MinBackoff, policy.MaxBackoff = time.Minute, time.Minute
+	client := NewClient(WithBaseURL(server.URL), WithRetryPolicy(policy))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := client.LookupContext(ctx, "RG1 1AF")
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, model.ErrTransport) {
+		t.Fatalf("LookupContext() error = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("LookupContext() returned after %v, want once the context ended", elapsed)
+	}
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("calls = %d, want 1", got)
+	}
+}
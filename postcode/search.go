@@ -0,0 +1,309 @@
+package postcode
+
+import (
+	"context"
+	"github.com/razorcorp/postcode-sdk-go/model"
+	"github.com/razorcorp/postcode-sdk-go/postcode/internal"
+	"math"
+	"sort"
+	"sync"
+)
+
+const (
+	//tileRadius largest radius in metres accepted by reverse geocoding, covering a single tile of an area search
+	tileRadius = 2000
+
+	//tileLimit largest number of postcodes returned by reverse geocoding. A tile returning this many postcodes may
+	//have more and is split into quarters.
+	tileLimit = 100
+
+	//minTileRadius radius in metres below which saturated tiles are no longer split
+	minTileRadius = 50
+
+	//earthRadius mean radius of the Earth in metres
+	earthRadius = 6371008.8
+
+	//metresPerDegree length of a degree of latitude in metres
+	metresPerDegree = 111320.0
+)
+
+type (
+	//BoundingBox rectangular area between two latitudes and two longitudes
+	BoundingBox struct {
+		South float64 `json:"south"`
+		West  float64 `json:"west"`
+		North float64 `json:"north"`
+		East  float64 `json:"east"`
+	}
+
+	//tile part of a search area covered by a single reverse geocoding request
+	tile BoundingBox
+)
+
+//SearchRadius Returns the postcodes within the given radius in metres of the given centre, beyond the 2,000m radius
+//and 100 results limits of ReverseGeocoding.
+//
+//The area is covered by a grid of overlapping reverse geocoding requests; tiles returning 100 postcodes are split
+//into quarters until every tile returns fewer. The results are de-duplicated, their Distance set to the distance from
+//the centre in metres and sorted nearest first. A max greater than 0 limits the number of results.
+//
+//A tile still returning 100 postcodes once split down to its smallest size may hold more than returned. The postcodes found are then
+//returned along with an error matching model.ErrTruncated.
+func (c *Client) SearchRadius(centre Geocode, radius float64, max int) ([]model.Postcode, *model.ResponseError) {
+	return c.SearchRadiusContext(context.Background(), centre, radius, max)
+}
+
+//SearchRadiusContext is like SearchRadius but carries the given context through to the requests
+func (c *Client) SearchRadiusContext(ctx context.Context, centre Geocode, radius float64, max int) (result []model.Postcode, err *model.ResponseError) {
+	ctx, span := c.startOperation(ctx, "SearchRadius", 0)
+	defer func() { endOperation(span, result, err) }()
+
+	if err := centre.validate(); err != nil {
+		return nil, err
+	}
+	if radius <= 0 {
+		return nil, internal.ValidationError("Radius must be greater than 0")
+	}
+	if max < 0 {
+		return nil, internal.ValidationError("Maximum results must not be negative")
+	}
+
+	latitude, longitude := centre.Latitude, centre.Longitude
+	latitudeDelta := radius / metresPerDegree
+	longitudeDelta := radius / (metresPerDegree * math.Cos(latitude*math.Pi/180))
+	box := BoundingBox{
+		South: latitude - latitudeDelta,
+		West:  longitude - longitudeDelta,
+		North: latitude + latitudeDelta,
+		East:  longitude + longitudeDelta,
+	}
+
+	reaches := func(t tile) bool {
+		lat, lon := t.centre()
+		return haversine(latitude, longitude, lat, lon) <= radius+t.radius()
+	}
+	keep := func(p model.Postcode) bool {
+		return haversine(latitude, longitude, p.Latitude, p.Longitude) <= radius
+	}
+	return c.search(ctx, box, latitude, longitude, reaches, keep, max)
+}
+
+//SearchBoundingBox Returns the postcodes within the given bounding box, beyond the 2,000m radius and 100 results
+//limits of ReverseGeocoding.
+//
+//Works like SearchRadius, with the Distance of the results measured from the centre of the box.
+func (c *Client) SearchBoundingBox(box BoundingBox, max int) ([]model.Postcode, *model.ResponseError) {
+	return c.SearchBoundingBoxContext(context.Background(), box, max)
+}
+
+//SearchBoundingBoxContext is like SearchBoundingBox but carries the given context through to the requests
+func (c *Client) SearchBoundingBoxContext(ctx context.Context, box BoundingBox, max int) (result []model.Postcode, err *model.ResponseError) {
+	ctx, span := c.startOperation(ctx, "SearchBoundingBox", 0)
+	defer func() { endOperation(span, result, err) }()
+
+	if err := box.validate(); err != nil {
+		return nil, err
+	}
+	if max < 0 {
+		return nil, internal.ValidationError("Maximum results must not be negative")
+	}
+
+	latitude, longitude := tile(box).centre()
+	reaches := func(tile) bool {
+		return true
+	}
+	keep := func(p model.Postcode) bool {
+		return p.Latitude >= box.South && p.Latitude <= box.North && p.Longitude >= box.West && p.Longitude <= box.East
+	}
+	return c.search(ctx, box, latitude, longitude, reaches, keep, max)
+}
+
+//search reverse geocodes each tile of the given box that reaches the search area, up to the configured concurrency
+//of tiles at once, and collects the postcodes to keep sorted by distance from the given centre
+func (c *Client) search(ctx context.Context, box BoundingBox, latitude, longitude float64, reaches func(tile) bool, keep func(model.Postcode) bool, max int) ([]model.Postcode, *model.ResponseError) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	var searchErr *model.ResponseError
+	var truncated bool
+	found := make(map[string]model.Postcode)
+	collect := func(postcodes []model.Postcode, saturated bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		truncated = truncated || saturated
+		for _, postcode := range postcodes {
+			if _, ok := found[postcode.Postcode]; ok || !keep(postcode) {
+				continue
+			}
+			postcode.Distance = haversine(latitude, longitude, postcode.Latitude, postcode.Longitude)
+			found[postcode.Postcode] = postcode
+		}
+	}
+
+	semaphore := make(chan struct{}, c.parallelism())
+	var wg sync.WaitGroup
+	for _, t := range box.tiles() {
+		if !reaches(t) {
+			continue
+		}
+		// waits for a free slot only as long as the context lasts
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if err := ctx.Err(); err != nil {
+			mu.Lock()
+			if searchErr == nil {
+				searchErr = internal.TransportError(err)
+			}
+			mu.Unlock()
+			break
+		}
+		wg.Add(1)
+		go func(t tile) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			if err := c.searchTile(ctx, t, collect); err != nil {
+				mu.Lock()
+				if searchErr == nil {
+					searchErr = err
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}(t)
+	}
+	wg.Wait()
+
+	if searchErr != nil {
+		return nil, searchErr
+	}
+
+	results := make([]model.Postcode, 0, len(found))
+	for _, postcode := range found {
+		results = append(results, postcode)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Distance != results[j].Distance {
+			return results[i].Distance < results[j].Distance
+		}
+		return results[i].Postcode < results[j].Postcode
+	})
+	if max > 0 && len(results) > max {
+		results = results[:max]
+	}
+	if truncated {
+		return results, &model.ResponseError{
+			Message: "Search area too dense: the smallest tiles still returned 100 postcodes",
+			Kind:    model.ErrTruncated,
+		}
+	}
+	return results, nil
+}
+
+//searchTile reverse geocodes the given tile, splitting it into quarters while it returns the maximum number of
+//postcodes. The postcodes of a tile too small to be split are collected as saturated.
+func (c *Client) searchTile(ctx context.Context, t tile, collect func([]model.Postcode, bool)) *model.ResponseError {
+	latitude, longitude := t.centre()
+	radius := t.radius()
+	data, err := c.ReverseGeocodingContext(ctx, Geocode{
+		Latitude:  latitude,
+		Longitude: longitude,
+		Limit:     tileLimit,
+		Radius:    int64(math.Ceil(radius)),
+	})
+	if err != nil {
+		return err
+	}
+
+	saturated := len(data) >= tileLimit
+	if saturated && radius/2 >= minTileRadius {
+		for _, quarter := range t.split() {
+			if err := c.searchTile(ctx, quarter, collect); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	collect(data, saturated)
+	return nil
+}
+
+func (b BoundingBox) validate() *model.ResponseError {
+	if b.South >= b.North || b.West >= b.East {
+		return internal.ValidationError("Bounding box must have South below North and West below East")
+	}
+	if b.South < -90 || b.North > 90 || b.West < -180 || b.East > 180 {
+		return internal.ValidationError("Bounding box must be within valid latitudes and longitudes")
+	}
+	return nil
+}
+
+//tiles divides the box into a grid of tiles, each small enough to be covered by a single reverse geocoding request
+func (b BoundingBox) tiles() []tile {
+	// side of the square inscribed in a circle of tileRadius, with a margin for the curvature of the Earth
+	side := tileRadius * math.Sqrt2 * 0.99
+
+	// widest latitude of the box, where a degree of longitude is the longest
+	widest := math.Min(math.Abs(b.South), math.Abs(b.North))
+	if b.South < 0 && b.North > 0 {
+		widest = 0
+	}
+
+	height := (b.North - b.South) * metresPerDegree
+	width := (b.East - b.West) * metresPerDegree * math.Cos(widest*math.Pi/180)
+	rows := int(math.Ceil(height / side))
+	columns := int(math.Ceil(width / side))
+
+	latitudeStep := (b.North - b.South) / float64(rows)
+	longitudeStep := (b.East - b.West) / float64(columns)
+	tiles := make([]tile, 0, rows*columns)
+	for row := 0; row < rows; row++ {
+		for column := 0; column < columns; column++ {
+			tiles = append(tiles, tile{
+				South: b.South + float64(row)*latitudeStep,
+				West:  b.West + float64(column)*longitudeStep,
+				North: b.South + float64(row+1)*latitudeStep,
+				East:  b.West + float64(column+1)*longitudeStep,
+			})
+		}
+	}
+	return tiles
+}
+
+func (t tile) centre() (latitude, longitude float64) {
+	return (t.South + t.North) / 2, (t.West + t.East) / 2
+}
+
+//radius returns the distance in metres from the centre of the tile to its farthest corner
+func (t tile) radius() float64 {
+	latitude, longitude := t.centre()
+	return math.Max(
+		haversine(latitude, longitude, t.South, t.West),
+		haversine(latitude, longitude, t.North, t.West),
+	)
+}
+
+func (t tile) split() []tile {
+	latitude, longitude := t.centre()
+	return []tile{
+		{South: t.South, West: t.West, North: latitude, East: longitude},
+		{South: t.South, West: longitude, North: latitude, East: t.East},
+		{South: latitude, West: t.West, North: t.North, East: longitude},
+		{South: latitude, West: longitude, North: t.North, East: t.East},
+	}
+}
+
+//haversine returns the great-circle distance in metres between two points
+func haversine(latitude1, longitude1, latitude2, longitude2 float64) float64 {
+	phi1 := latitude1 * math.Pi / 180
+	phi2 := latitude2 * math.Pi / 180
+	deltaPhi := (latitude2 - latitude1) * math.Pi / 180
+	deltaLambda := (longitude2 - longitude1) * math.Pi / 180
+
+	a := math.Sin(deltaPhi/2)*math.Sin(deltaPhi/2) +
+		math.Cos(phi1)*math.Cos(phi2)*math.Sin(deltaLambda/2)*math.Sin(deltaLambda/2)
+	return 2 * earthRadius * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
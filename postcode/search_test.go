@@ -0,0 +1,130 @@
+package postcode
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/razorcorp/postcode-sdk-go/model"
+)
+
+//geocodingServer answers reverse geocoding requests from the given postcodes, nearest first, as postcodes.io does
+func geocodingServer(t *testing.T, postcodes []model.Postcode) (*httptest.Server, *int32) {
+	t.Helper()
+	calls := new(int32)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		query := r.URL.Query()
+		latitude, _ := strconv.ParseFloat(query.Get("lat"), 64)
+		longitude, _ := strconv.ParseFloat(query.Get("lon"), 64)
+		radius, _ := strconv.ParseFloat(query.Get("radius"), 64)
+		limit, _ := strconv.Atoi(query.Get("limit"))
+
+		var found []model.Postcode
+		for _, postcode := range postcodes {
+			postcode.Distance = haversine(latitude, longitude, postcode.Latitude, postcode.Longitude)
+			if postcode.Distance <= radius {
+				found = append(found, postcode)
+			}
+		}
+		sort.Slice(found, func(i, j int) bool {
+			return found[i].Distance < found[j].Distance
+		})
+		if len(found) > limit {
+			found = found[:limit]
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"status": 200, "result": found})
+	}))
+	t.Cleanup(server.Close)
+	return server, calls
+}
+
+//grid returns n by n postcodes spaced by the given metres around the centre
+func grid(centre Geocode, n int, spacing float64) []model.Postcode {
+	var postcodes []model.Postcode
+	for row := 0; row < n; row++ {
+		for column := 0; column < n; column++ {
+			north := (float64(row) - float64(n-1)/2) * spacing
+			east := (float64(column) - float64(n-1)/2) * spacing
+			postcodes = append(postcodes, model.Postcode{
+				Postcode:  fmt.Sprintf("P%d-%d", row, column),
+				Latitude:  centre.Latitude + north/metresPerDegree,
+				Longitude: centre.Longitude + east/(metresPerDegree*math.Cos(centre.Latitude*math.Pi/180)),
+			})
+		}
+	}
+	return postcodes
+}
+
+func TestSearchRadiusSplitsSaturatedTiles(t *testing.T) {
+	centre := Geocode{Latitude: 51.4116, Longitude: -0.7482}
+	// 400 postcodes 50m apart, four times the limit of a single request
+	server, calls := geocodingServer(t, grid(centre, 20, 50))
+	client := NewClient(WithBaseURL(server.URL))
+
+	results, err := client.SearchRadius(centre, 400, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := 0
+	for _, postcode := range grid(centre, 20, 50) {
+		if haversine(centre.Latitude, centre.Longitude, postcode.Latitude, postcode.Longitude) <= 400 {
+			want++
+		}
+	}
+	if len(results) != want {
+		t.Errorf("SearchRadius() = %d postcodes, want all %d within 400m", len(results), want)
+	}
+	for i := 1; i < len(results); i++ {
+		if results[i].Distance < results[i-1].Distance {
+			t.Fatalf("SearchRadius() results not sorted by distance at %d", i)
+		}
+	}
+	if atomic.LoadInt32(calls) < 5 {
+		t.Errorf("requests = %d, want the saturated tile split", atomic.LoadInt32(calls))
+	}
+
+	limited, err := client.SearchRadius(centre, 400, 10)
+	if err != nil || len(limited) != 10 || limited[0].Postcode != results[0].Postcode {
+		t.Errorf("SearchRadius() with max = %d postcodes, %v, want the nearest 10", len(limited), err)
+	}
+}
+
+func TestSearchRadiusTruncated(t *testing.T) {
+	centre := Geocode{Latitude: 51.4116, Longitude: -0.7482}
+	// 225 postcodes 2m apart cannot be covered by tiles of 50m
+	server, _ := geocodingServer(t, grid(centre, 15, 2))
+	client := NewClient(WithBaseURL(server.URL))
+
+	results, err := client.SearchRadius(centre, 100, 0)
+	if !errors.Is(err, model.ErrTruncated) {
+		t.Errorf("SearchRadius() error = %v, want ErrTruncated", err)
+	}
+	if len(results) < tileLimit {
+		t.Errorf("SearchRadius() = %d postcodes, want the postcodes found returned with the error", len(results))
+	}
+}
+
+func TestSearchCancelled(t *testing.T) {
+	centre := Geocode{Latitude: 51.4116, Longitude: -0.7482}
+	server, calls := geocodingServer(t, nil)
+	client := NewClient(WithBaseURL(server.URL), WithConcurrency(1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := client.SearchRadiusContext(ctx, centre, 10000, 0)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("SearchRadiusContext() error = %v, want context.Canceled", err)
+	}
+	if n := atomic.LoadInt32(calls); n != 0 {
+		t.Errorf("requests = %d, want none once cancelled", n)
+	}
+}
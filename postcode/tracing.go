@@ -0,0 +1,133 @@
+package postcode
+
+import (
+	"context"
+	"encoding/hex"
+	"github.com/razorcorp/postcode-sdk-go/model"
+	"reflect"
+)
+
+type (
+	//Tracer starts spans around the operations of a Client, each of their requests and HTTP attempts, e.g. by adapting
+	//an OpenTelemetry tracer. Implementations must be safe for concurrent use.
+	Tracer interface {
+		//Start starts a span with the given name as a child of the span carried by the given context, and returns a
+		//context carrying the new span
+		Start(ctx context.Context, name string) (context.Context, Span)
+	}
+
+	//Span is a single operation, request or HTTP attempt being traced
+	Span interface {
+		//SetAttribute records an attribute of the span, e.g. endpoint or batch_size
+		SetAttribute(key string, value interface{})
+
+		//SpanContext returns the identity of the span, propagated in the traceparent header of the HTTP attempts
+		SpanContext() SpanContext
+
+		//End ends the span with the error that failed it, or nil
+		End(err error)
+	}
+
+	//SpanContext identity of a span as propagated by the W3C Trace Context traceparent header
+	SpanContext struct {
+		TraceID [16]byte
+		SpanID  [8]byte
+		Sampled bool
+	}
+
+	noopTracer struct{}
+	noopSpan   struct{}
+)
+
+//WithTracer traces every operation of the Client with the given tracer, e.g. a postcodes.Lookup span served from the
+//cache, with a child span for each request to the API and each of its HTTP attempts. Operations built on other ones,
+//such as LookupAll, are parents of their spans. The W3C traceparent header of the attempt span is sent with each
+//request. Without a tracer nothing is traced.
+func WithTracer(tracer Tracer) Option {
+	return func(c *Client) {
+		c.tracer = tracer
+	}
+}
+
+//IsValid reports whether the span context has a trace ID and a span ID
+func (s SpanContext) IsValid() bool {
+	return s.TraceID != [16]byte{} && s.SpanID != [8]byte{}
+}
+
+//TraceParent returns the W3C traceparent header value of the span context, e.g.
+//00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+func (s SpanContext) TraceParent() string {
+	flags := "00"
+	if s.Sampled {
+		flags = "01"
+	}
+	return "00-" + hex.EncodeToString(s.TraceID[:]) + "-" + hex.EncodeToString(s.SpanID[:]) + "-" + flags
+}
+
+func (noopTracer) Start(ctx context.Context, _ string) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+func (noopSpan) SetAttribute(string, interface{}) {}
+
+func (noopSpan) SpanContext() SpanContext {
+	return SpanContext{}
+}
+
+func (noopSpan) End(error) {}
+
+//startSpan starts a span with the configured tracer
+func (c *Client) startSpan(ctx context.Context, name string) (context.Context, Span) {
+	if c.tracer == nil {
+		return noopTracer{}.Start(ctx, name)
+	}
+	return c.tracer.Start(ctx, name)
+}
+
+//endSpan ends the span with the given error, avoiding a non-nil error interface holding a nil *model.ResponseError
+func endSpan(span Span, err *model.ResponseError) {
+	if err != nil {
+		span.SetAttribute("http.status_code", err.Status)
+		span.End(err)
+		return
+	}
+	span.End(nil)
+}
+
+//resultCount returns the number of results decoded into data: the length of a slice, otherwise 1
+func resultCount(data interface{}) int {
+	value := reflect.ValueOf(data)
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+	if value.Kind() == reflect.Slice {
+		return value.Len()
+	}
+	return 1
+}
+
+//startOperation starts the span of the named SDK operation, e.g. postcodes.Lookup, the parent of the spans of its
+//requests and nested operations. The number of items given to a bulk operation is recorded as batch_size.
+func (c *Client) startOperation(ctx context.Context, name string, size int) (context.Context, Span) {
+	ctx, span := c.startSpan(ctx, "postcodes."+name)
+	span.SetAttribute("operation", name)
+	if size > 0 {
+		span.SetAttribute("batch_size", size)
+	}
+	return ctx, span
+}
+
+//endOperation ends the span of an operation with its error, recording the number of results when it succeeded
+func endOperation(span Span, data interface{}, err *model.ResponseError) {
+	if err == nil {
+		span.SetAttribute("result_count", resultCount(data))
+	}
+	endSpan(span, err)
+}
+
+//endStreamOperation ends the span of an operation reporting failures per item, such as LookupAll or Enrich, with the
+//number of results it produced
+func endStreamOperation(span Span, count int) {
+	span.SetAttribute("result_count", count)
+	span.End(nil)
+}
@@ -0,0 +1,187 @@
+package postcode
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type (
+	//recordingTracer records the spans started, with the name of their parent
+	recordingTracer struct {
+		mu    sync.Mutex
+		spans []*recordedSpan
+	}
+
+	recordedSpan struct {
+		tracer     *recordingTracer
+		name       string
+		parent     string
+		attributes map[string]interface{}
+		context    SpanContext
+		ended      bool
+		err        error
+	}
+
+	recordedSpanKey struct{}
+)
+
+func (t *recordingTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	span := &recordedSpan{tracer: t, name: name, attributes: make(map[string]interface{})}
+	if parent, ok := ctx.Value(recordedSpanKey{}).(*recordedSpan); ok {
+		span.parent = parent.name
+		span.context.TraceID = parent.context.TraceID
+	} else {
+		span.context.TraceID[0] = byte(len(t.spans) + 1)
+	}
+	span.context.SpanID[0] = byte(len(t.spans) + 1)
+	span.context.Sampled = true
+	t.spans = append(t.spans, span)
+	return context.WithValue(ctx, recordedSpanKey{}, span), span
+}
+
+func (s *recordedSpan) SetAttribute(key string, value interface{}) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.attributes[key] = value
+}
+
+func (s *recordedSpan) SpanContext() SpanContext {
+	return s.context
+}
+
+func (s *recordedSpan) End(err error) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.ended, s.err = true, err
+}
+
+//find returns the first span of the given name, failing the test if there is none
+func (t *recordingTracer) find(tb testing.TB, name string) *recordedSpan {
+	tb.Helper()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, span := range t.spans {
+		if span.name == name {
+			return span
+		}
+	}
+	tb.Fatalf("no %s span among %v", name, t.names())
+	return nil
+}
+
+func (t *recordingTracer) names() []string {
+	names := make([]string, 0, len(t.spans))
+	for _, span := range t.spans {
+		names = append(names, span.name)
+	}
+	return names
+}
+
+func TestTracingOperationSpans(t *testing.T) {
+	var traceParent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceParent = r.Header.Get("traceparent")
+		w.Write([]byte(lookupBody))
+	}))
+	defer server.Close()
+
+	tracer := new(recordingTracer)
+	client := NewClient(WithBaseURL(server.URL), WithTracer(tracer))
+	if _, err := client.Lookup("RG1 1AF"); err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+
+	operation := tracer.find(t, "postcodes.Lookup")
+	request := tracer.find(t, "postcodes GET postcodes/:postcode")
+	attempt := tracer.find(t, "postcodes attempt GET postcodes/:postcode")
+	if operation.parent != "" || request.parent != operation.name || attempt.parent != request.name {
+		t.Errorf("parents = %q, %q, %q, want operation, request and attempt nested", operation.parent, request.parent, attempt.parent)
+	}
+	if !operation.ended || operation.err != nil || operation.attributes["result_count"] != 1 {
+		t.Errorf("operation span = %+v, want ended with 1 result", operation)
+	}
+	if want := attempt.context.TraceParent(); traceParent != want {
+		t.Errorf("traceparent = %q, want %q", traceParent, want)
+	}
+}
+
+func TestTracingCacheHit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(lookupBody))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithCache(NewMemoryCache(10), DefaultCacheTTL))
+	if _, err := client.Lookup("RG1 1AF"); err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+
+	tracer := new(recordingTracer)
+	client.tracer = tracer
+	if _, err := client.Lookup("RG1 1AF"); err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if names := tracer.names(); len(names) != 1 || names[0] != "postcodes.Lookup" {
+		t.Errorf("spans of a cache hit = %v, want the operation span only", names)
+	}
+}
+
+func TestTracingCompositeOperation(t *testing.T) {
+	server, _ := bulkServer(t, nil)
+	tracer := new(recordingTracer)
+	client := NewClient(WithBaseURL(server.URL), WithTracer(tracer))
+
+	results := client.LookupAll([]string{"RG1 1AF", "RG1 1AZ", "RG12 2PE"}, nil)
+	if len(results) != 3 {
+		t.Fatalf("LookupAll() = %d results, want 3", len(results))
+	}
+
+	all := tracer.find(t, "postcodes.LookupAll")
+	bulk := tracer.find(t, "postcodes.BulkLookup")
+	request := tracer.find(t, "postcodes POST postcodes")
+	if bulk.parent != all.name || request.parent != bulk.name {
+		t.Errorf("parents = %q, %q, want LookupAll, BulkLookup and request nested", bulk.parent, request.parent)
+	}
+	if all.attributes["batch_size"] != 3 || all.attributes["result_count"] != 3 || !all.ended {
+		t.Errorf("LookupAll span attributes = %v, want batch_size and result_count of 3", all.attributes)
+	}
+}
+
+func TestTracingStreamOperation(t *testing.T) {
+	server, _ := bulkServer(t, nil)
+	tracer := new(recordingTracer)
+	client := NewClient(WithBaseURL(server.URL), WithTracer(tracer))
+
+	postcodes := make(chan string, 2)
+	postcodes <- "RG1 1AF"
+	postcodes <- "RG1 1AZ"
+	close(postcodes)
+	for range client.Enrich(context.Background(), postcodes) {
+	}
+
+	// the span ends right after the output channel is closed
+	deadline := time.Now().Add(time.Second)
+	enrich := tracer.find(t, "postcodes.Enrich")
+	for {
+		tracer.mu.Lock()
+		ended, count := enrich.ended, enrich.attributes["result_count"]
+		tracer.mu.Unlock()
+		if ended {
+			if count != 2 {
+				t.Errorf("result_count = %v, want 2", count)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Enrich span not ended")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}